@@ -49,7 +49,7 @@ func isEmptyValue(in interface{}) bool {
 }
 
 func identifierFromColumn(
-    tagValueBSFields refl.GroupedFieldsWithBS,
+    descriptor *ModelDescriptor,
     object Base,
     columnName string,
 ) BaseIdentifier {
@@ -60,12 +60,10 @@ func identifierFromColumn(
         Value: nil,
     }
 
-    if bsField, ok := tagValueBSFields[columnName]; ok {
+    if modelField, ok := descriptor.Field(columnName); ok {
         identifier.Exists = true
 
-        fieldName := bsField.Name()
-        objVal := reflect.ValueOf(object)
-        field := objVal.Elem().FieldByName(fieldName)
+        field := modelField.ValueFrom(reflect.ValueOf(object))
 
         if field.IsValid() {
             deepVal := field
@@ -108,22 +106,14 @@ func identifierFromBase(object Base) []BaseIdentifier {
         return []BaseIdentifier{identifier}
     }
 
-    columns := []string{"id"}
-    if composite, ok := object.(CompositeKey); ok {
-        columns = composite.CompositeKey()
+    descriptor, err := DescriptorFor(object)
+    if err != nil {
+        return nil
     }
 
-    fieldGroupings := refl.GetGroupedFieldsWithBS(
-        object,
-        refl.GroupFieldsByTagValue("db"),
-    )
-    tagValueBSFields := fieldGroupings[0]
-
     var identifiers []BaseIdentifier
-    for _, columnName := range columns {
-        identifier := identifierFromColumn(
-            *tagValueBSFields, object, columnName,
-        )
+    for _, columnName := range descriptor.KeyColumns() {
+        identifier := identifierFromColumn(descriptor, object, columnName)
         identifiers = append(identifiers, identifier)
     }
 
@@ -134,18 +124,19 @@ func setIdentifierOnBase(object Base, newIdValue interface{}) error {
     if v, ok := object.(TraditionalID); ok {
         return v.SetID(newIdValue)
     }
-    fieldGroupings := refl.GetGroupedFieldsWithBS(
-        object,
-        refl.GroupFieldsByTagValue("db"),
-    )
-    tagValueBSFields := fieldGroupings[0]
-    fieldName := (*tagValueBSFields)["id"].Name()
-    objVal := reflect.ValueOf(object)
-    field := objVal.Elem().FieldByName(fieldName)
-    if !field.IsValid() {
+    descriptor, err := DescriptorFor(object)
+    if err != nil {
+        return err
+    }
+    modelField, ok := descriptor.Field("id")
+    if !ok {
+        return errors.New("Object provided doesn't have an 'id' column.")
+    }
+    field := modelField.ValueFrom(reflect.ValueOf(object))
+    if !field.IsValid() || !field.CanSet() {
         return errors.Errorf(
             "Field in returned data '%s' is not valid.",
-            fieldName,
+            modelField.Name(),
         )
     }
 
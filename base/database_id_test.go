@@ -0,0 +1,113 @@
+package base_test
+
+import (
+    "database/sql/driver"
+    "math"
+
+    . "github.com/onsi/ginkgo"
+    . "github.com/onsi/gomega"
+
+    "github.com/daihasso/machgo/base"
+)
+
+type testUUID [16]byte
+
+type testScannerID struct {
+    raw string
+}
+
+func (self *testScannerID) Scan(src interface{}) error {
+    self.raw = src.(string)
+    return nil
+}
+
+type testValuerID struct {
+    raw int64
+}
+
+func (self testValuerID) Value() (driver.Value, error) {
+    return self.raw, nil
+}
+
+type stringIDObject struct {
+    Id string `db:"id"`
+}
+
+type int32IDObject struct {
+    Id int32 `db:"id"`
+}
+
+type uint16PtrIDObject struct {
+    Id *uint16 `db:"id"`
+}
+
+type uuidIDObject struct {
+    Id testUUID `db:"id"`
+}
+
+type scannerIDObject struct {
+    Id testScannerID `db:"id"`
+}
+
+var _ = Describe("SetId", func() {
+    It("should set string ids", func() {
+        object := &stringIDObject{}
+        Expect(base.SetId(object, "abc")).To(Succeed())
+        Expect(object.Id).To(Equal("abc"))
+
+        Expect(base.SetId(object, []byte("def"))).To(Succeed())
+        Expect(object.Id).To(Equal("def"))
+    })
+
+    It("should convert between int widths", func() {
+        object := &int32IDObject{}
+        Expect(base.SetId(object, int64(42))).To(Succeed())
+        Expect(object.Id).To(Equal(int32(42)))
+
+        Expect(base.SetId(object, uint8(7))).To(Succeed())
+        Expect(object.Id).To(Equal(int32(7)))
+    })
+
+    It("should refuse to overflow", func() {
+        object := &int32IDObject{}
+        err := base.SetId(object, int64(math.MaxInt32)+1)
+        Expect(err).To(HaveOccurred())
+        Expect(err.Error()).To(MatchRegexp("overflows int32"))
+
+        ptrObject := &uint16PtrIDObject{}
+        Expect(base.SetId(ptrObject, -1)).ToNot(Succeed())
+        Expect(base.SetId(ptrObject, int64(65535))).To(Succeed())
+        Expect(*ptrObject.Id).To(Equal(uint16(65535)))
+    })
+
+    It("should set UUID-like ids from strings and bytes", func() {
+        expected := testUUID{
+            0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3,
+            0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00,
+        }
+        object := &uuidIDObject{}
+        err := base.SetId(object, "123e4567-e89b-12d3-a456-426614174000")
+        Expect(err).ToNot(HaveOccurred())
+        Expect(object.Id).To(Equal(expected))
+
+        object = &uuidIDObject{}
+        Expect(base.SetId(object, expected[:])).To(Succeed())
+        Expect(object.Id).To(Equal(expected))
+
+        stringObject := &stringIDObject{}
+        Expect(base.SetId(stringObject, expected)).To(Succeed())
+        Expect(stringObject.Id).To(Equal(
+            "123e4567-e89b-12d3-a456-426614174000",
+        ))
+    })
+
+    It("should use sql.Scanner targets and driver.Valuer sources", func() {
+        object := &scannerIDObject{}
+        Expect(base.SetId(object, "xyz")).To(Succeed())
+        Expect(object.Id.raw).To(Equal("xyz"))
+
+        intObject := &int32IDObject{}
+        Expect(base.SetId(intObject, testValuerID{raw: 9})).To(Succeed())
+        Expect(intObject.Id).To(Equal(int32(9)))
+    })
+})
@@ -0,0 +1,186 @@
+package base
+
+import (
+    "crypto/rand"
+    "encoding/binary"
+    "encoding/hex"
+    "sync"
+    "time"
+
+    "github.com/pkg/errors"
+)
+
+const (
+    snowflakeWorkerBits = 10
+    snowflakeSequenceBits = 12
+    snowflakeMaxWorkerID = 1<<snowflakeWorkerBits - 1
+    snowflakeMaxSequence = 1<<snowflakeSequenceBits - 1
+)
+
+// DefaultSnowflakeEpoch is the epoch used by snowflake generators that don't
+// specify their own.
+var DefaultSnowflakeEpoch = time.Date(
+    2020, time.January, 1, 0, 0, 0, 0, time.UTC,
+)
+
+var defaultSnowflakeOnce sync.Once
+var defaultSnowflakeMutex *sync.RWMutex
+var defaultSnowflakeGenerator *SnowflakeGenerator
+
+func randomBytes(count int) []byte {
+    raw := make([]byte, count)
+    _, err := rand.Read(raw)
+    if err != nil {
+        panic(errors.Wrap(err, "Error reading random bytes for ID"))
+    }
+
+    return raw
+}
+
+func formatUUID(raw []byte) string {
+    encoded := hex.EncodeToString(raw)
+    return encoded[0:8] + "-" + encoded[8:12] + "-" + encoded[12:16] + "-" +
+        encoded[16:20] + "-" + encoded[20:32]
+}
+
+// NewUUIDv4 generates a random (version 4) UUID in its canonical string form.
+func NewUUIDv4() string {
+    raw := randomBytes(16)
+    raw[6] = (raw[6] & 0x0f) | 0x40
+    raw[8] = (raw[8] & 0x3f) | 0x80
+
+    return formatUUID(raw)
+}
+
+// NewUUIDv7 generates a time-ordered (version 7) UUID in its canonical string
+// form.
+func NewUUIDv7() string {
+    raw := randomBytes(16)
+    millis := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+    timestamp := make([]byte, 8)
+    binary.BigEndian.PutUint64(timestamp, millis)
+    copy(raw[0:6], timestamp[2:8])
+    raw[6] = (raw[6] & 0x0f) | 0x70
+    raw[8] = (raw[8] & 0x3f) | 0x80
+
+    return formatUUID(raw)
+}
+
+// UUIDv4ID can be embedded in an object to generate random UUIDs for its id.
+type UUIDv4ID struct {}
+func (UUIDv4ID) NewID() interface{} {
+    return NewUUIDv4()
+}
+
+// UUIDv7ID can be embedded in an object to generate time-ordered UUIDs for
+// its id.
+type UUIDv7ID struct {}
+func (UUIDv7ID) NewID() interface{} {
+    return NewUUIDv7()
+}
+
+// SnowflakeGenerator generates roughly time-ordered int64 ids made up of a
+// millisecond timestamp, a worker id and a per-millisecond sequence.
+type SnowflakeGenerator struct {
+    workerID int64
+    epoch time.Time
+
+    mutex sync.Mutex
+    lastTimestamp,
+    sequence int64
+}
+
+func (self *SnowflakeGenerator) millisSinceEpoch() int64 {
+    return int64(time.Since(self.epoch) / time.Millisecond)
+}
+
+// Next generates the next id.
+func (self *SnowflakeGenerator) Next() int64 {
+    self.mutex.Lock()
+    defer self.mutex.Unlock()
+
+    timestamp := self.millisSinceEpoch()
+    if timestamp < self.lastTimestamp {
+        // The clock went backwards; keep handing out ids from the last
+        // timestamp we saw rather than risking duplicates.
+        timestamp = self.lastTimestamp
+    }
+
+    if timestamp == self.lastTimestamp {
+        self.sequence = (self.sequence + 1) & snowflakeMaxSequence
+        if self.sequence == 0 {
+            for timestamp <= self.lastTimestamp {
+                time.Sleep(time.Millisecond / 10)
+                timestamp = self.millisSinceEpoch()
+            }
+        }
+    } else {
+        self.sequence = 0
+    }
+    self.lastTimestamp = timestamp
+
+    return timestamp<<(snowflakeWorkerBits+snowflakeSequenceBits) |
+        self.workerID<<snowflakeSequenceBits |
+        self.sequence
+}
+
+// NewID generates the next id; this satisfies IDGenerator.
+func (self *SnowflakeGenerator) NewID() interface{} {
+    return self.Next()
+}
+
+// NewSnowflakeGenerator creates a new SnowflakeGenerator for the provided
+// worker. A zero epoch uses DefaultSnowflakeEpoch.
+func NewSnowflakeGenerator(
+    workerID int64, epoch time.Time,
+) (*SnowflakeGenerator, error) {
+    if workerID < 0 || workerID > snowflakeMaxWorkerID {
+        return nil, errors.Errorf(
+            "Snowflake worker id must be between 0 and %d not %d",
+            snowflakeMaxWorkerID,
+            workerID,
+        )
+    }
+    if epoch.IsZero() {
+        epoch = DefaultSnowflakeEpoch
+    }
+    if epoch.After(time.Now()) {
+        return nil, errors.New("Snowflake epoch can't be in the future.")
+    }
+
+    return &SnowflakeGenerator{
+        workerID: workerID,
+        epoch: epoch,
+    }, nil
+}
+
+// SetDefaultSnowflakeGenerator sets the generator used by SnowflakeID.
+func SetDefaultSnowflakeGenerator(generator *SnowflakeGenerator) {
+    defaultSnowflakeMutex.Lock()
+    defer defaultSnowflakeMutex.Unlock()
+    defaultSnowflakeGenerator = generator
+}
+
+// DefaultSnowflakeGenerator retrieves the generator used by SnowflakeID.
+func DefaultSnowflakeGenerator() *SnowflakeGenerator {
+    defaultSnowflakeMutex.RLock()
+    defer defaultSnowflakeMutex.RUnlock()
+    return defaultSnowflakeGenerator
+}
+
+// SnowflakeID can be embedded in an object to generate snowflake ids for its
+// id using the default generator (worker 0 unless configured with
+// SetDefaultSnowflakeGenerator).
+type SnowflakeID struct {}
+func (SnowflakeID) NewID() interface{} {
+    return DefaultSnowflakeGenerator().NewID()
+}
+
+func init() {
+    defaultSnowflakeOnce.Do(func() {
+        defaultSnowflakeMutex = new(sync.RWMutex)
+        defaultSnowflakeGenerator, _ = NewSnowflakeGenerator(
+            0, DefaultSnowflakeEpoch,
+        )
+    })
+}
@@ -0,0 +1,89 @@
+package base_test
+
+import (
+    "time"
+
+    . "github.com/onsi/ginkgo"
+    . "github.com/onsi/gomega"
+
+    "github.com/daihasso/machgo/base"
+)
+
+var uuidRegex = `^[0-9a-f]{8}-[0-9a-f]{4}-%s[0-9a-f]{3}-[89ab][0-9a-f]{3}-` +
+    `[0-9a-f]{12}$`
+
+type uuidv4Object struct {
+    base.UUIDv4ID
+
+    Id string `db:"id"`
+}
+
+type uuidv7Object struct {
+    base.UUIDv7ID
+
+    Id testUUID `db:"id"`
+}
+
+type snowflakeObject struct {
+    base.SnowflakeID
+
+    Id int64 `db:"id"`
+}
+
+var _ = Describe("ID generators", func() {
+    It("should generate version 4 UUIDs", func() {
+        Expect(base.NewUUIDv4()).To(MatchRegexp(uuidRegex, "4"))
+        Expect(base.NewUUIDv4()).ToNot(Equal(base.NewUUIDv4()))
+    })
+
+    It("should generate time-ordered version 7 UUIDs", func() {
+        first := base.NewUUIDv7()
+        time.Sleep(2 * time.Millisecond)
+        second := base.NewUUIDv7()
+        Expect(first).To(MatchRegexp(uuidRegex, "7"))
+        Expect(first < second).To(BeTrue())
+    })
+
+    It("should generate increasing snowflake ids", func() {
+        generator, err := base.NewSnowflakeGenerator(5, time.Time{})
+        Expect(err).ToNot(HaveOccurred())
+
+        seen := make(map[int64]bool)
+        last := int64(0)
+        for i := 0; i < 5000; i++ {
+            id := generator.Next()
+            Expect(id).To(BeNumerically(">", last))
+            Expect((id >> 12) & 1023).To(Equal(int64(5)))
+            seen[id] = true
+            last = id
+        }
+        Expect(seen).To(HaveLen(5000))
+    })
+
+    It("should validate snowflake configuration", func() {
+        _, err := base.NewSnowflakeGenerator(1024, time.Time{})
+        Expect(err).To(HaveOccurred())
+
+        _, err = base.NewSnowflakeGenerator(
+            1, time.Now().Add(time.Hour),
+        )
+        Expect(err).To(HaveOccurred())
+    })
+
+    It("should initialize ids of objects embedding generators", func() {
+        v4Object := &uuidv4Object{}
+        _, err := base.InitializeId(v4Object)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(v4Object.Id).To(MatchRegexp(uuidRegex, "4"))
+
+        v7Object := &uuidv7Object{}
+        _, err = base.InitializeId(v7Object)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(v7Object.Id[6] >> 4).To(Equal(byte(7)))
+
+        snowflake := &snowflakeObject{}
+        _, err = base.InitializeId(snowflake)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(snowflake.Id).ToNot(BeZero())
+    })
+})
@@ -0,0 +1,70 @@
+package base
+
+import (
+    "reflect"
+    "sort"
+    "sync"
+
+    "github.com/pkg/errors"
+)
+
+var linkModelsMutex sync.RWMutex
+var linkModels = make(map[reflect.Type]*ModelDescriptor)
+var linkModelsVersion uint64
+
+// RegisterLinkModels declares models (usually link tables such as a PostImage
+// between Post and Image) whose relationships may be used to join other
+// models through them. Queries only add tables that weren't joined
+// explicitly when they're one of these models.
+func RegisterLinkModels(objects ...Base) error {
+    descriptors := make([]*ModelDescriptor, len(objects))
+    for i, object := range objects {
+        descriptor, err := DescriptorFor(object)
+        if err != nil {
+            return err
+        }
+        if _, err := descriptor.Table(); err != nil {
+            return errors.Wrapf(
+                err, "Can't register link model of type %T", object,
+            )
+        }
+        descriptors[i] = descriptor
+    }
+
+    linkModelsMutex.Lock()
+    defer linkModelsMutex.Unlock()
+    for _, descriptor := range descriptors {
+        if _, ok := linkModels[descriptor.Type()]; ok {
+            continue
+        }
+        linkModels[descriptor.Type()] = descriptor
+        linkModelsVersion++
+    }
+
+    return nil
+}
+
+// LinkModels retrieves the descriptors of the models registered with
+// RegisterLinkModels sorted by table name along with a version which changes
+// whenever more are registered.
+func LinkModels() ([]*ModelDescriptor, uint64) {
+    linkModelsMutex.RLock()
+    descriptors := make([]*ModelDescriptor, 0, len(linkModels))
+    for _, descriptor := range linkModels {
+        descriptors = append(descriptors, descriptor)
+    }
+    version := linkModelsVersion
+    linkModelsMutex.RUnlock()
+
+    sort.Slice(descriptors, func(i, j int) bool {
+        iTable, _ := descriptors[i].Table()
+        jTable, _ := descriptors[j].Table()
+        if iTable == jTable {
+            return descriptors[i].Type().String() <
+                descriptors[j].Type().String()
+        }
+        return iTable < jTable
+    })
+
+    return descriptors, version
+}
@@ -0,0 +1,250 @@
+package base
+
+import (
+    "reflect"
+    "sort"
+    "sync"
+
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/refl"
+)
+
+var modelRegistryOnce sync.Once
+var modelRegistryMutex *sync.RWMutex
+var modelRegistry map[reflect.Type]*ModelDescriptor
+
+// ModelField is the cached description of a single tagged field on a model.
+// It is derived from the model's type so it never holds instance values.
+type ModelField struct {
+    name,
+    column string
+    index []int
+    typ reflect.Type
+    tags map[string]*refl.BSTag
+}
+
+// Name is the name of the struct field.
+func (self ModelField) Name() string {
+    return self.name
+}
+
+// Column is the db column the field maps to.
+func (self ModelField) Column() string {
+    return self.column
+}
+
+// Index is the index path of the field suitable for reflect's FieldByIndex.
+func (self ModelField) Index() []int {
+    return self.index
+}
+
+// Type is the type of the struct field.
+func (self ModelField) Type() reflect.Type {
+    return self.typ
+}
+
+// Tag retrieves the parsed tag with the provided name if it exists.
+func (self ModelField) Tag(name string) *refl.BSTag {
+    if bsTag, ok := self.tags[name]; ok {
+        return bsTag
+    }
+    return nil
+}
+
+// Foreign indicates the field's column lives on another table.
+func (self ModelField) Foreign() bool {
+    if bsTag := self.Tag("db"); bsTag != nil {
+        return bsTag.HasProperty("foreign")
+    }
+    return false
+}
+
+// ValueFrom retrieves the field's value from the provided model value.
+func (self ModelField) ValueFrom(objVal reflect.Value) reflect.Value {
+    for objVal.Kind() == reflect.Ptr {
+        objVal = objVal.Elem()
+    }
+    return objVal.FieldByIndex(self.index)
+}
+
+// ModelDescriptor is a compiled, per-type description of a model. It is built
+// once per type and shared so that hot paths don't need to walk struct fields
+// and parse tags repeatedly.
+type ModelDescriptor struct {
+    typ reflect.Type
+    table string
+    tableErr error
+    fields []*ModelField
+    columnFields map[string]*ModelField
+    keyColumns []string
+
+    columnBSFields,
+    fieldNameBSFields *refl.GroupedFieldsWithBS
+
+    relationshipsOnce sync.Once
+    relationships []Relationship
+}
+
+// Type is the (dereferenced) type this descriptor describes.
+func (self *ModelDescriptor) Type() reflect.Type {
+    return self.typ
+}
+
+// Table is the table name for the model.
+func (self *ModelDescriptor) Table() (string, error) {
+    return self.table, self.tableErr
+}
+
+// Fields are the db tagged fields of the model in struct order.
+func (self *ModelDescriptor) Fields() []*ModelField {
+    return self.fields
+}
+
+// Field retrieves the field which maps to the provided column.
+func (self *ModelDescriptor) Field(column string) (*ModelField, bool) {
+    field, ok := self.columnFields[column]
+    return field, ok
+}
+
+// KeyColumns are the column(s) that identify the model.
+func (self *ModelDescriptor) KeyColumns() []string {
+    return self.keyColumns
+}
+
+// ColumnBSFields are the model's fields grouped by their db tag value.
+func (self *ModelDescriptor) ColumnBSFields() *refl.GroupedFieldsWithBS {
+    return self.columnBSFields
+}
+
+// FieldNameBSFields are the model's fields grouped by their field name.
+func (self *ModelDescriptor) FieldNameBSFields() *refl.GroupedFieldsWithBS {
+    return self.fieldNameBSFields
+}
+
+// Relationships are the relationships the model declares (if any). They are
+// resolved lazily because building them may require other descriptors.
+func (self *ModelDescriptor) Relationships() []Relationship {
+    self.relationshipsOnce.Do(func() {
+        zero := reflect.New(self.typ).Interface()
+        if relationshipable, ok := zero.(Relationshipable); ok {
+            self.relationships = relationshipable.Relationships()
+        }
+    })
+
+    return self.relationships
+}
+
+func guessTable(object Base) (string, error) {
+    if customTabler, ok := object.(CustomTabler); ok {
+        return customTabler.TableName(), nil
+    }
+    return refl.GuessTableName(object)
+}
+
+func compileModelDescriptor(typ reflect.Type) *ModelDescriptor {
+    zero := reflect.New(typ).Interface()
+    descriptor := &ModelDescriptor{
+        typ: typ,
+        columnFields: make(map[string]*ModelField),
+        keyColumns: []string{"id"},
+    }
+    descriptor.table, descriptor.tableErr = guessTable(zero)
+
+    if composite, ok := zero.(CompositeKey); ok {
+        descriptor.keyColumns = composite.CompositeKey()
+    }
+
+    if typ.Kind() != reflect.Struct {
+        emptyColumns := make(refl.GroupedFieldsWithBS)
+        emptyNames := make(refl.GroupedFieldsWithBS)
+        descriptor.columnBSFields = &emptyColumns
+        descriptor.fieldNameBSFields = &emptyNames
+        return descriptor
+    }
+
+    fieldGroupings := refl.GetGroupedFieldsWithBS(
+        zero,
+        refl.GroupFieldsByTagValue("db", "dbfkey"),
+        refl.GroupFieldsByFieldName(),
+    )
+    descriptor.columnBSFields = fieldGroupings[0]
+    descriptor.fieldNameBSFields = fieldGroupings[1]
+
+    // Fields are grouped by both their `db` and `dbfkey` tags so the same
+    // field can appear more than once.
+    seenFields := make(map[string]bool, len(*descriptor.columnBSFields))
+    for _, bsField := range *descriptor.columnBSFields {
+        if seenFields[bsField.Name()] {
+            continue
+        }
+        seenFields[bsField.Name()] = true
+
+        dbTag := bsField.Tag("db")
+        structField, _ := typ.FieldByName(bsField.Name())
+        tags := make(map[string]*refl.BSTag, len(bsField.Tags()))
+        for _, bsTag := range bsField.Tags() {
+            tags[bsTag.Name()] = bsTag
+        }
+
+        field := &ModelField{
+            name: bsField.Name(),
+            column: dbTag.Value(),
+            index: structField.Index,
+            typ: structField.Type,
+            tags: tags,
+        }
+        descriptor.fields = append(descriptor.fields, field)
+        descriptor.columnFields[field.column] = field
+    }
+
+    sort.Slice(descriptor.fields, func(i, j int) bool {
+        return descriptor.fields[i].index[0] < descriptor.fields[j].index[0]
+    })
+
+    return descriptor
+}
+
+// DescriptorForType retrieves the ModelDescriptor for the provided type,
+// compiling and registering it if this is the first time it's been seen.
+func DescriptorForType(typ reflect.Type) *ModelDescriptor {
+    typ = refl.DerefDeep(typ)
+
+    modelRegistryMutex.RLock()
+    descriptor, ok := modelRegistry[typ]
+    modelRegistryMutex.RUnlock()
+    if ok {
+        return descriptor
+    }
+
+    // NOTE: Compiling happens outside of the lock since it may call back into
+    //       user code (TableName, CompositeKey) which could in turn need a
+    //       descriptor. If two goroutines race the first one stored wins.
+    compiled := compileModelDescriptor(typ)
+
+    modelRegistryMutex.Lock()
+    defer modelRegistryMutex.Unlock()
+    if descriptor, ok := modelRegistry[typ]; ok {
+        return descriptor
+    }
+    modelRegistry[typ] = compiled
+
+    return compiled
+}
+
+// DescriptorFor retrieves the ModelDescriptor for the provided object.
+func DescriptorFor(object Base) (*ModelDescriptor, error) {
+    typ := reflect.TypeOf(object)
+    if typ == nil {
+        return nil, errors.New("Can't describe a nil object.")
+    }
+
+    return DescriptorForType(typ), nil
+}
+
+func init() {
+    modelRegistryOnce.Do(func() {
+        modelRegistryMutex = new(sync.RWMutex)
+        modelRegistry = make(map[reflect.Type]*ModelDescriptor)
+    })
+}
@@ -0,0 +1,97 @@
+package base_test
+
+import (
+    "reflect"
+    "sync"
+
+    . "github.com/onsi/ginkgo"
+    . "github.com/onsi/gomega"
+
+    "github.com/daihasso/machgo/base"
+)
+
+type descriptorTestObject struct {
+    Id int64 `db:"id"`
+    Name string `db:"name"`
+    PostId int64 `db:"post_id,foreign" dbforeign:"posts"`
+
+    ignored string
+}
+
+type descriptorForeignKeyObject struct {
+    Id int64 `db:"id"`
+    OwnerId int64 `db:"owner_id" dbfkey:"owners.id"`
+}
+
+type descriptorCompositeObject struct {
+    PostId int64 `db:"post_id"`
+    ImageId int64 `db:"image_id"`
+}
+
+func (descriptorCompositeObject) CompositeKey() []string {
+    return []string{"post_id", "image_id"}
+}
+
+var _ = Describe("ModelDescriptor", func() {
+    It("should describe a simple model", func() {
+        descriptor, err := base.DescriptorFor(&descriptorTestObject{})
+        Expect(err).ToNot(HaveOccurred())
+
+        table, err := descriptor.Table()
+        Expect(err).ToNot(HaveOccurred())
+        Expect(table).To(Equal("descriptor_test_objects"))
+        Expect(descriptor.KeyColumns()).To(Equal([]string{"id"}))
+        Expect(descriptor.Fields()).To(HaveLen(3))
+
+        field, ok := descriptor.Field("post_id")
+        Expect(ok).To(BeTrue())
+        Expect(field.Name()).To(Equal("PostId"))
+        Expect(field.Index()).To(Equal([]int{2}))
+        Expect(field.Foreign()).To(BeTrue())
+        Expect(field.Tag("dbforeign").Value()).To(Equal("posts"))
+    })
+
+    It("should describe foreign key columns once", func() {
+        descriptor, err := base.DescriptorFor(&descriptorForeignKeyObject{})
+        Expect(err).ToNot(HaveOccurred())
+
+        columns := make([]string, len(descriptor.Fields()))
+        for i, field := range descriptor.Fields() {
+            columns[i] = field.Column()
+        }
+        Expect(columns).To(Equal([]string{"id", "owner_id"}))
+    })
+
+    It("should use composite keys when declared", func() {
+        descriptor, err := base.DescriptorFor(&descriptorCompositeObject{})
+        Expect(err).ToNot(HaveOccurred())
+        Expect(descriptor.KeyColumns()).To(Equal(
+            []string{"post_id", "image_id"},
+        ))
+    })
+
+    It("should only compile a type once", func() {
+        var wg sync.WaitGroup
+        descriptors := make([]*base.ModelDescriptor, 10)
+        for i := range descriptors {
+            wg.Add(1)
+            go func(i int) {
+                defer GinkgoRecover()
+                defer wg.Done()
+                descriptors[i] = base.DescriptorForType(
+                    reflect.TypeOf(&descriptorTestObject{}),
+                )
+            }(i)
+        }
+        wg.Wait()
+
+        for _, descriptor := range descriptors {
+            Expect(descriptor).To(BeIdenticalTo(descriptors[0]))
+        }
+    })
+
+    It("should fail to describe nil", func() {
+        _, err := base.DescriptorFor(nil)
+        Expect(err).To(HaveOccurred())
+    })
+})
@@ -0,0 +1,168 @@
+package base
+
+import (
+    "reflect"
+    "sort"
+
+    "github.com/pkg/errors"
+)
+
+// ColumnInfo describes a single column of a model.
+type ColumnInfo struct {
+    // Name is the column name in the database.
+    Name string
+    // Field is the name of the struct field the column maps to.
+    Field string
+    // Type is the Go type of the struct field.
+    Type reflect.Type
+    // Foreign indicates the column is pulled from another table.
+    Foreign bool
+    // ForeignTable is the table a foreign column is pulled from.
+    ForeignTable string
+}
+
+// RelationshipInfo describes a relationship declared by a model.
+type RelationshipInfo struct {
+    Name,
+    SelfTable,
+    SelfColumn,
+    TargetTable,
+    TargetColumn string
+    SelfType,
+    TargetType reflect.Type
+}
+
+// ModelInfo is a read-only description of a model suitable for tooling. It is
+// a snapshot; modifying it has no effect on how machgo treats the model.
+type ModelInfo struct {
+    Type reflect.Type
+    Table string
+    Columns []ColumnInfo
+    KeyColumns []string
+    DatabaseManagedID bool
+    Relationships []RelationshipInfo
+}
+
+// Column retrieves the info for the column with the provided name.
+func (self ModelInfo) Column(name string) (ColumnInfo, bool) {
+    for _, column := range self.Columns {
+        if column.Name == name {
+            return column, true
+        }
+    }
+
+    return ColumnInfo{}, false
+}
+
+func modelInfoFromDescriptor(
+    descriptor *ModelDescriptor,
+) (*ModelInfo, error) {
+    table, err := descriptor.Table()
+    if err != nil {
+        return nil, errors.Wrap(err, "Error determining table for model")
+    }
+
+    zero := reflect.New(descriptor.Type()).Interface()
+    _, databaseManagedID := zero.(DatabaseIDGenerator)
+    if _, ok := zero.(DatabaseFuncIDGenerator); ok {
+        databaseManagedID = true
+    }
+
+    info := &ModelInfo{
+        Type: descriptor.Type(),
+        Table: table,
+        KeyColumns: append([]string(nil), descriptor.KeyColumns()...),
+        DatabaseManagedID: databaseManagedID,
+    }
+
+    for _, field := range descriptor.Fields() {
+        column := ColumnInfo{
+            Name: field.Column(),
+            Field: field.Name(),
+            Type: field.Type(),
+            Foreign: field.Foreign(),
+        }
+        if foreignTag := field.Tag("dbforeign"); foreignTag != nil {
+            column.ForeignTable = foreignTag.Value()
+        }
+        info.Columns = append(info.Columns, column)
+    }
+
+    for _, relationship := range descriptor.Relationships() {
+        selfTable, targetTable := relationship.Tables()
+        selfColumn, targetColumn := relationship.Columns()
+        selfType, targetType := relationship.Types()
+        info.Relationships = append(info.Relationships, RelationshipInfo{
+            Name: relationship.Name(),
+            SelfTable: selfTable,
+            SelfColumn: selfColumn,
+            TargetTable: targetTable,
+            TargetColumn: targetColumn,
+            SelfType: selfType,
+            TargetType: targetType,
+        })
+    }
+
+    return info, nil
+}
+
+// Describe returns a description of the provided model registering it if it
+// hasn't been seen before.
+func Describe(object Base) (*ModelInfo, error) {
+    descriptor, err := DescriptorFor(object)
+    if err != nil {
+        return nil, err
+    }
+
+    return modelInfoFromDescriptor(descriptor)
+}
+
+// RegisterModels registers the provided models ahead of time so they're
+// included in RegisteredModels before they've otherwise been used.
+func RegisterModels(objects ...Base) error {
+    for _, object := range objects {
+        descriptor, err := DescriptorFor(object)
+        if err != nil {
+            return err
+        }
+        if _, err := descriptor.Table(); err != nil {
+            return errors.Wrapf(
+                err, "Can't register model of type %T", object,
+            )
+        }
+    }
+
+    return nil
+}
+
+// RegisteredModels describes every model machgo currently knows about sorted
+// by table name. Types that can't be resolved to a table are skipped.
+func RegisteredModels() []*ModelInfo {
+    modelRegistryMutex.RLock()
+    descriptors := make([]*ModelDescriptor, 0, len(modelRegistry))
+    for _, descriptor := range modelRegistry {
+        descriptors = append(descriptors, descriptor)
+    }
+    modelRegistryMutex.RUnlock()
+
+    var infos []*ModelInfo
+    for _, descriptor := range descriptors {
+        if descriptor.Type().Kind() != reflect.Struct {
+            continue
+        }
+        info, err := modelInfoFromDescriptor(descriptor)
+        if err != nil {
+            continue
+        }
+        infos = append(infos, info)
+    }
+
+    sort.Slice(infos, func(i, j int) bool {
+        if infos[i].Table == infos[j].Table {
+            return infos[i].Type.String() < infos[j].Type.String()
+        }
+        return infos[i].Table < infos[j].Table
+    })
+
+    return infos
+}
@@ -0,0 +1,92 @@
+package base_test
+
+import (
+    "reflect"
+
+    . "github.com/onsi/ginkgo"
+    . "github.com/onsi/gomega"
+
+    "github.com/daihasso/machgo/base"
+)
+
+type describeTestPost struct {
+    base.DatabaseManagedID
+
+    Id int64 `db:"id"`
+    Title string `db:"title"`
+}
+
+type describeTestImage struct {
+    Id int64 `db:"id"`
+    PostId int64 `db:"post_id"`
+}
+
+func (self *describeTestImage) Relationships() []base.Relationship {
+    return []base.Relationship{
+        base.MustRelationship(self, "post_id", &describeTestPost{}, "id"),
+    }
+}
+
+var _ = Describe("Describe", func() {
+    It("should describe a model's table, columns and keys", func() {
+        info, err := base.Describe(&describeTestPost{})
+        Expect(err).ToNot(HaveOccurred())
+        Expect(info.Table).To(Equal("describe_test_posts"))
+        Expect(info.KeyColumns).To(Equal([]string{"id"}))
+        Expect(info.DatabaseManagedID).To(BeTrue())
+        Expect(info.Columns).To(HaveLen(2))
+
+        column, ok := info.Column("title")
+        Expect(ok).To(BeTrue())
+        Expect(column.Field).To(Equal("Title"))
+        Expect(column.Type).To(Equal(reflect.TypeOf("")))
+    })
+
+    It("should describe relationships", func() {
+        info, err := base.Describe(&describeTestImage{})
+        Expect(err).ToNot(HaveOccurred())
+        Expect(info.DatabaseManagedID).To(BeFalse())
+        Expect(info.Relationships).To(HaveLen(1))
+        Expect(info.Relationships[0].SelfColumn).To(Equal("post_id"))
+        Expect(info.Relationships[0].TargetTable).To(
+            Equal("describe_test_posts"),
+        )
+    })
+
+    It("should enumerate registered models", func() {
+        err := base.RegisterModels(&describeTestPost{}, &describeTestImage{})
+        Expect(err).ToNot(HaveOccurred())
+
+        var tables []string
+        for _, info := range base.RegisteredModels() {
+            tables = append(tables, info.Table)
+        }
+        Expect(tables).To(ContainElement("describe_test_posts"))
+        Expect(tables).To(ContainElement("describe_test_images"))
+    })
+
+    It("should enumerate link models separately", func() {
+        err := base.RegisterLinkModels(&describeTestImage{})
+        Expect(err).ToNot(HaveOccurred())
+
+        descriptors, version := base.LinkModels()
+        var types []reflect.Type
+        for _, descriptor := range descriptors {
+            types = append(types, descriptor.Type())
+        }
+        Expect(types).To(ContainElement(reflect.TypeOf(describeTestImage{})))
+        Expect(types).ToNot(
+            ContainElement(reflect.TypeOf(describeTestPost{})),
+        )
+
+        err = base.RegisterLinkModels(&describeTestImage{})
+        Expect(err).ToNot(HaveOccurred())
+        _, sameVersion := base.LinkModels()
+        Expect(sameVersion).To(Equal(version))
+    })
+
+    It("should fail to register a model without a table", func() {
+        err := base.RegisterModels(&struct{ Id int64 `db:"id"` }{})
+        Expect(err).To(HaveOccurred())
+    })
+})
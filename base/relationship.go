@@ -14,8 +14,11 @@ type typeTableColumn struct {
     table string
 }
 
-// Relationship is a representation of how two objects join together.
+// Relationship is a representation of how two objects join together. It may
+// optionally be named to tell apart several relationships between the same
+// two objects.
 type Relationship struct {
+    name string
     selfInfo,
     targetInfo typeTableColumn
 }
@@ -27,11 +30,18 @@ type Relationship struct {
 //     baz.fizz=foo.bar
 func (self Relationship) Invert() *Relationship {
     return &Relationship {
+        name: self.name,
         selfInfo: self.targetInfo,
         targetInfo: self.selfInfo,
     }
 }
 
+// Name is the name of this relationship; it's empty for unnamed
+// relationships.
+func (self Relationship) Name() string {
+    return self.name
+}
+
 func (self Relationship) Tables() (string, string) {
     return self.selfInfo.table, self.targetInfo.table
 }
@@ -51,6 +61,18 @@ type Relationshipable interface {
 
 func NewRelationship(
     self Base, selfColumn string, target Base, targetColumn string,
+) (*Relationship, error) {
+    return NewNamedRelationship("", self, selfColumn, target, targetColumn)
+}
+
+// NewNamedRelationship creates a relationship with a name which can be used
+// to pick it when an object has several relationships to the same target.
+func NewNamedRelationship(
+    name string,
+    self Base,
+    selfColumn string,
+    target Base,
+    targetColumn string,
 ) (*Relationship, error) {
     selfTable, err := BaseTable(self)
     if err != nil {
@@ -78,6 +100,7 @@ func NewRelationship(
     }
 
     return &Relationship{
+        name: name,
         selfInfo: selfInfo,
         targetInfo: targetInfo,
     }, nil
@@ -86,33 +109,24 @@ func NewRelationship(
 func MustRelationship(
     self Base, selfColumn string, target Base, targetColumn string,
 ) Relationship {
-    selfTable, err := BaseTable(self)
-    if err != nil {
-        panic(errors.Wrap(
-            err, "Error determining table for self in relationship",
-        ))
-    }
-    targetTable, err := BaseTable(target)
-    if err != nil {
-        panic(errors.Wrap(
-            err, "Error determining table for target in relationship",
-        ))
-    }
-
-    targetInfo := typeTableColumn{
-        typ: refl.Deref(reflect.TypeOf(target)),
-        column: targetColumn,
-        table: targetTable,
-    }
+    return MustNamedRelationship("", self, selfColumn, target, targetColumn)
+}
 
-    selfInfo := typeTableColumn{
-        typ: refl.Deref(reflect.TypeOf(self)),
-        column: selfColumn,
-        table: selfTable,
+// MustNamedRelationship is the same as NewNamedRelationship but panics on
+// errors.
+func MustNamedRelationship(
+    name string,
+    self Base,
+    selfColumn string,
+    target Base,
+    targetColumn string,
+) Relationship {
+    relationship, err := NewNamedRelationship(
+        name, self, selfColumn, target, targetColumn,
+    )
+    if err != nil {
+        panic(err)
     }
 
-    return Relationship{
-        selfInfo: selfInfo,
-        targetInfo: targetInfo,
-    }
+    return *relationship
 }
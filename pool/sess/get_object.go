@@ -12,22 +12,22 @@ import (
 
 var getObjectStatementTemplate = `SELECT * FROM %s WHERE %s`
 
-func getObject(
-    target base.Base, idValue interface{}, session *Session,
-) error {
-    var err error
-
+// getObjectStatement builds the statement and values to read the object
+// with the provided id.
+func getObjectStatement(
+    target base.Base, idValue interface{},
+) (string, map[string]interface{}, error) {
     identifiers := base.GetId(target)
     if len(identifiers) > 1 {
-        return errors.New("Can't use get with a composite object")
+        return "", nil, errors.New("Can't use get with a composite object")
     }
     identifier := identifiers[0]
     if !identifier.Exists {
-        return errors.New(
+        return "", nil, errors.New(
             "Object provided to GetObject doesn't have an identifier.",
         )
     } else if identifier.IsSet {
-        return errors.New(
+        return "", nil, errors.New(
             "Object provided to GetObject has an identifier set, it should " +
                 "be a new instance with no identifier.",
         )
@@ -35,7 +35,7 @@ func getObject(
 
     if identifier.Value != nil &&
         reflect.TypeOf(identifier.Value) != reflect.TypeOf(idValue) {
-        return errors.Errorf(
+        return "", nil, errors.Errorf(
             "Type of provided id (%T) does not match identifier type for " +
                 "object (%T).",
             idValue,
@@ -47,7 +47,9 @@ func getObject(
 
     tableName, err := base.BaseTable(target)
     if err != nil {
-        return errors.Wrap(err, "Error while trying to get table name")
+        return "", nil, errors.Wrap(
+            err, "Error while trying to get table name",
+        )
     }
 
     whereClause := fmt.Sprintf("%s = :%s", idColumn, idColumn)
@@ -60,32 +62,53 @@ func getObject(
         idColumn: idValue,
     }
 
+    return statement, values, nil
+}
+
+// readObject reads the result of the provided statement into the target in
+// the provided transaction.
+func readObject(
+    tx *sqlx.Tx,
+    target base.Base,
+    statement string,
+    values map[string]interface{},
+) error {
+    statement = tx.Rebind(statement)
+
+    rows, err := tx.NamedQuery(statement, values)
+    if err != nil {
+        return errors.Wrap(
+            err, "Error while reading data from DB",
+        )
+    }
+    defer rows.Close()
+
+    if !rows.Next() {
+        return errors.New(
+            "No results from DB for object with provided id.",
+        )
+    }
+
+    err = rows.StructScan(target)
+    if err != nil {
+        return errors.Wrap(
+            err, "Error while reading data from DB into struct",
+        )
+    }
+
+    return nil
+}
+
+func getObject(
+    target base.Base, idValue interface{}, session *Session,
+) error {
+    statement, values, err := getObjectStatement(target, idValue)
+    if err != nil {
+        return err
+    }
+
     err = session.Transactionized(func(tx *sqlx.Tx) error {
-        var err error
-        statement = tx.Rebind(statement)
-
-        rows, err := tx.NamedQuery(statement, values)
-        if err != nil {
-            return errors.Wrap(
-                err, "Error while reading data from DB",
-            )
-        }
-        defer rows.Close()
-
-        if !rows.Next() {
-            return errors.New(
-                "No results from DB for object with provided id.",
-            )
-        }
-
-        err = rows.StructScan(target)
-        if err != nil {
-            return errors.Wrap(
-                err, "Error while reading data from DB into struct",
-            )
-        }
-
-        return nil
+        return readObject(tx, target, statement, values)
     })
     if err != nil {
         return err
@@ -99,6 +122,24 @@ func getObject(
     return nil
 }
 
+// getObjectForUpdate reads the object with the provided id in the provided
+// transaction and locks its row until the transaction ends.
+func getObjectForUpdate(
+    tx *sqlx.Tx, target base.Base, idValue interface{},
+) error {
+    statement, values, err := getObjectStatement(target, idValue)
+    if err != nil {
+        return err
+    }
+
+    err = readObject(tx, target, statement + " FOR UPDATE", values)
+    if err != nil {
+        return err
+    }
+
+    return setObjectSaved(target)
+}
+
 // GetObject gets the object with the provided id from the DB using a new
 // session from the global connection pool.
 func GetObject(object base.Base, idValue interface{}) error {
@@ -111,3 +152,16 @@ func GetObject(object base.Base, idValue interface{}) error {
 
     return getObject(object, idValue, session)
 }
+
+// GetObjectForUpdate gets the object with the provided id from the DB in the
+// provided transaction and locks its row against other updates until the
+// transaction ends. It's meant to be used within Transactionized:
+//   err := Transactionized(func(tx *sqlx.Tx) error {
+//       err := GetObjectForUpdate(tx, &object, id)
+//       ...
+//   })
+func GetObjectForUpdate(
+    tx *sqlx.Tx, object base.Base, idValue interface{},
+) error {
+    return getObjectForUpdate(tx, object, idValue)
+}
@@ -66,6 +66,31 @@ var _ = Describe("GetObject", func() {
             Expect(object.Id).To(Equal(objectID))
         })
 
+        It("Should be able to get and lock an object", func() {
+            objectID := rand.Int63()
+            expectedQ := `SELECT \* FROM test_objects WHERE id = \? ` +
+                `FOR UPDATE`
+            object := testObject{}
+            mock.ExpectBegin()
+            mock.ExpectQuery(expectedQ).WithArgs(
+                objectID,
+            ).WillReturnRows(
+                sqlmock.NewRows(
+                    []string{"id", "name"},
+                ).AddRow(
+                    int64(objectID), "foo",
+                ),
+            )
+            mock.ExpectCommit()
+            err := Transactionized(func(tx *sqlx.Tx) error {
+                return GetObjectForUpdate(tx, &object, objectID)
+            })
+            Expect(err).ToNot(HaveOccurred())
+            Expect(object.Name).To(Equal("foo"))
+            Expect(Saved(&object)).To(BeTrue())
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+
         It("Should fail when Id is set", func() {
             expectedError := "Object provided to GetObject has an " +
                 "identifier set, it should be a new instance with no" +
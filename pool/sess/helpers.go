@@ -4,11 +4,11 @@ import (
     "database/sql"
     "fmt"
     "math/rand"
+    "reflect"
     "sort"
 
     "github.com/daihasso/machgo/query/qtypes"
     "github.com/daihasso/machgo/base"
-    "github.com/daihasso/machgo/refl"
     "github.com/daihasso/machgo/types"
 )
 
@@ -33,23 +33,32 @@ func processSortedNamedValues(
     //       might not be good.
     tagValueArg := make(map[string]*sql.NamedArg)
 
-    refl.GetFieldsByTagWithTagValues(
-        object,
-        "db",
-        func(name string, tagValueInterface refl.TagValueInterface) {
-            in := tagValueInterface.Interface
-            if _, ok := in.(types.Nullable); !ok && tagValueInterface.IsNil() {
-                return
-            }
-
-            tagValue := tagValueInterface.TagValue
-            randomNumber := rand.Int() // #nosec: G404
-            variableName := fmt.Sprintf("%s_%d", tagValue, randomNumber)
-            tagValues = append(tagValues, tagValue)
-            namedValue := sql.Named(variableName, in)
-            tagValueArg[tagValue] = &namedValue
-        },
-    )
+    descriptor, err := base.DescriptorFor(object)
+    if err != nil {
+        return
+    }
+
+    objVal := reflect.ValueOf(object)
+    for _, field := range descriptor.Fields() {
+        if field.Foreign() {
+            // TODO: Handle this more elegantly.
+            continue
+        }
+
+        fieldVal := field.ValueFrom(objVal)
+        in := fieldVal.Interface()
+        isNil := fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil()
+        if _, ok := in.(types.Nullable); !ok && isNil {
+            continue
+        }
+
+        tagValue := field.Column()
+        randomNumber := rand.Int() // #nosec: G404
+        variableName := fmt.Sprintf("%s_%d", tagValue, randomNumber)
+        tagValues = append(tagValues, tagValue)
+        namedValue := sql.Named(variableName, in)
+        tagValueArg[tagValue] = &namedValue
+    }
 
     sort.Strings(tagValues)
 
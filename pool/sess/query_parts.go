@@ -3,6 +3,7 @@ package sess
 import (
     "database/sql"
     "fmt"
+    "sort"
     "strings"
 
     "github.com/daihasso/machgo/base"
@@ -25,6 +26,25 @@ func (self *QueryParts) AddValue(values ...sql.NamedArg) {
     }
 }
 
+// AddLiteral adds a column whose value is the literal statement provided
+// rather than a bound variable. The column is placed in sorted order with the
+// other columns and colons in the literal (such as `::uuid` casts) are
+// escaped so they aren't read as named variables.
+func (self *QueryParts) AddLiteral(
+    columnName string, literal base.LiteralStatement,
+) {
+    bindvar := strings.Replace(string(literal), ":", "::", -1)
+
+    i := sort.SearchStrings(self.ColumnNames, columnName)
+    self.ColumnNames = append(self.ColumnNames, "")
+    copy(self.ColumnNames[i+1:], self.ColumnNames[i:])
+    self.ColumnNames[i] = columnName
+
+    self.Bindvars = append(self.Bindvars, "")
+    copy(self.Bindvars[i+1:], self.Bindvars[i:])
+    self.Bindvars[i] = bindvar
+}
+
 func (self QueryParts) AsInsert() string {
     columns := strings.Join(self.ColumnNames, ", ")
     bindvars := strings.Join(self.Bindvars, ", ")
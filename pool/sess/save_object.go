@@ -62,14 +62,19 @@ func saveObject(object base.Base, session *Session) error {
     }
 
     var idColumns []string
+    idLiterals := make(map[string]base.LiteralStatement)
     for _, identifier := range identifiers {
         if !identifier.IsSet {
             idColumn := identifier.Column
-            if _, ok := object.(base.DatabaseIDGenerator); ok {
+            removeID := func(columnName string, _ *sql.NamedArg) bool {
+                return columnName == idColumn
+            }
+            if funcGen, ok := object.(base.DatabaseFuncIDGenerator); ok {
+                databaseManagedId = true
+                idLiterals[idColumn] = funcGen.DatabaseIDGenerationFunc()
+                columnFilters = append(columnFilters, removeID)
+            } else if _, ok := object.(base.DatabaseIDGenerator); ok {
                 databaseManagedId = true
-                removeID := func(columnName string, _ *sql.NamedArg) bool {
-                    return columnName == idColumn
-                }
                 columnFilters = append(columnFilters, removeID)
             } else {
                 return errors.New(
@@ -100,6 +105,11 @@ func saveObject(object base.Base, session *Session) error {
     //       approach or at least having it toggle-able in some way.
 
     queryParts := QueryPartsFromObject(object, columnFilters...)
+    for _, idColumn := range idColumns {
+        if literal, ok := idLiterals[idColumn]; ok {
+            queryParts.AddLiteral(idColumn, literal)
+        }
+    }
 
     query := fmt.Sprintf(
         saveObjectStatementTemplate, tableName, queryParts.AsInsert(),
@@ -226,6 +236,17 @@ func insertReadingId(
                 err, "Error while preparing query",
             )
         }
+        defer row.Close()
+
+        if !row.Next() {
+            err = row.Err()
+            if err == nil {
+                err = errors.New("No id returned from database")
+            }
+            return errors.Wrap(
+                err, "Error while reading returned id from database",
+            )
+        }
 
         err = row.StructScan(object)
         if err != nil {
@@ -11,6 +11,7 @@ import (
     "github.com/DATA-DOG/go-sqlmock"
     "github.com/jmoiron/sqlx"
 
+    "github.com/daihasso/machgo/base"
     "github.com/daihasso/machgo/pool/dbtype"
     "github.com/daihasso/machgo/pool"
     . "github.com/daihasso/machgo/pool/sess"
@@ -45,6 +46,24 @@ func (self testObjectWithIdGenerator) NewID() interface{} {
     return &self.testId
 }
 
+type testObjectFuncId struct {
+    Id int64 `db:"id"`
+    Name string `db:"name"`
+}
+
+func (testObjectFuncId) DatabaseIDGenerationFunc() base.LiteralStatement {
+    return "nextval('test_object_func_ids_id_seq')"
+}
+
+type testObjectUuidFuncId struct {
+    Id string `db:"id"`
+    Name string `db:"name"`
+}
+
+func (testObjectUuidFuncId) DatabaseIDGenerationFunc() base.LiteralStatement {
+    return "gen_random_uuid()::uuid"
+}
+
 type testObjectWithPtr struct {
     Id int64 `db:"id"`
     Name *string `db:"name"`
@@ -315,6 +334,55 @@ var _ = Describe("SaveObject", func() {
                 })
                 */
 
+                It("Should inline the id generation function and read " +
+                    "back the id", func() {
+                    objectID := rand.Int63()
+                    expectedQ := `INSERT INTO test_object_func_ids ` +
+                        `\(id, name\) VALUES ` +
+                        `\(nextval\('test_object_func_ids_id_seq'\), \?\) ` +
+                        `RETURNING id`
+                    object := testObjectFuncId{
+                        Name: "foo",
+                    }
+                    mock.ExpectBegin()
+                    mock.ExpectQuery(expectedQ).WithArgs(
+                        "foo",
+                    ).WillReturnRows(
+                        sqlmock.NewRows([]string{
+                            "id",
+                        }).AddRow(objectID),
+                    )
+                    mock.ExpectCommit()
+                    err := SaveObject(&object)
+                    Expect(err).ToNot(HaveOccurred())
+                    Expect(object.Id).To(Equal(objectID))
+                    Expect(Saved(&object)).To(BeTrue())
+                })
+
+                It("Should keep casts in the id generation " +
+                    "function", func() {
+                    objectID := "b7a4bf0e-5d0e-4b3c-9f0a-3c1c6c0e2a11"
+                    expectedQ := `INSERT INTO test_object_uuid_func_ids ` +
+                        `\(id, name\) VALUES ` +
+                        `\(gen_random_uuid\(\)::uuid, \?\) ` +
+                        `RETURNING id`
+                    object := testObjectUuidFuncId{
+                        Name: "foo",
+                    }
+                    mock.ExpectBegin()
+                    mock.ExpectQuery(expectedQ).WithArgs(
+                        "foo",
+                    ).WillReturnRows(
+                        sqlmock.NewRows([]string{
+                            "id",
+                        }).AddRow(objectID),
+                    )
+                    mock.ExpectCommit()
+                    err := SaveObject(&object)
+                    Expect(err).ToNot(HaveOccurred())
+                    Expect(object.Id).To(Equal(objectID))
+                })
+
                 It("Should handle an error while reading returned id " +
                     "gracefully", func() {
                     expectedError := errors.New("Database explosion.")
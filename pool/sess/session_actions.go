@@ -1,6 +1,8 @@
 package sess
 
 import (
+    "github.com/jmoiron/sqlx"
+
     "github.com/daihasso/machgo/base"
 )
 
@@ -19,6 +21,15 @@ func (self Session) GetObject(object base.Base, idValue interface{}) error {
     return getObject(object, idValue, &self)
 }
 
+// GetObjectForUpdate gets the object with the provided id from the DB in a
+// transaction from this session's Transactionized and locks its row until
+// that transaction ends.
+func (self Session) GetObjectForUpdate(
+    tx *sqlx.Tx, object base.Base, idValue interface{},
+) error {
+    return getObjectForUpdate(tx, object, idValue)
+}
+
 // UpdateObject updates the object provided in the DB.
 func (self Session) UpdateObject(object base.Base) error {
     return updateObject(object, &self)
@@ -0,0 +1,122 @@
+package query
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+
+    "github.com/pkg/errors"
+)
+
+var cteNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// commonTableExpression is a named query in a WITH clause. Recursive ones
+// are made up of an anchor query and a recursive query.
+type commonTableExpression struct {
+    name string
+    queries []*Subquery
+    recursive bool
+}
+
+func (self *Query) addCommonTableExpression(
+    name string, recursive bool, queries ...*Query,
+) *Query {
+    self.cached.Select.invalidate()
+
+    if !cteNameRegex.MatchString(name) {
+        self.Errors = append(self.Errors, errors.Errorf(
+            "Common table expression name '%s' is not valid", name,
+        ))
+        return self
+    }
+    for _, cte := range self.commonTableExpressions {
+        if cte.name == name {
+            self.Errors = append(self.Errors, errors.Errorf(
+                "Common table expression '%s' already exists", name,
+            ))
+            return self
+        }
+    }
+
+    subqueries := make([]*Subquery, len(queries))
+    for i, query := range queries {
+        if query == nil {
+            self.Errors = append(self.Errors, errors.Errorf(
+                "Common table expression '%s' can't use a nil query", name,
+            ))
+            return self
+        }
+        subqueries[i] = query.AsSubquery()
+        subqueries[i].plainColumns = true
+    }
+
+    self.commonTableExpressions = append(
+        self.commonTableExpressions,
+        commonTableExpression{
+            name: name,
+            queries: subqueries,
+            recursive: recursive,
+        },
+    )
+
+    return self
+}
+
+// With adds a common table expression with the provided name to the query.
+// It can be joined and selected like a table using CTE. Objects selected by
+// the provided query have their columns selected by their own names so that
+// the CTE can be read as the object's type.
+func (self *Query) With(name string, query *Query) *Query {
+    return self.addCommonTableExpression(name, false, query)
+}
+
+// WithRecursive adds a recursive common table expression with the provided
+// name to the query. It's made up of the rows of the anchor query combined
+// (with UNION ALL) with the rows of the recursive query which should join
+// onto the CTE itself using CTE.
+func (self *Query) WithRecursive(
+    name string, anchor, recursive *Query,
+) *Query {
+    return self.addCommonTableExpression(name, true, anchor, recursive)
+}
+
+// buildWith builds the WITH clause for the query's common table expressions
+// if there are any.
+func (self Query) buildWith() (string, []interface{}, error) {
+    if len(self.commonTableExpressions) == 0 {
+        return "", nil, nil
+    }
+
+    keyword := "WITH"
+    var (
+        cteStrings []string
+        args []interface{}
+    )
+    for _, cte := range self.commonTableExpressions {
+        if cte.recursive {
+            keyword = "WITH RECURSIVE"
+        }
+
+        queryStrings := make([]string, len(cte.queries))
+        for i, subquery := range cte.queries {
+            queryString, queryArgs, err := subquery.build(self.Tables)
+            if err != nil {
+                return "", nil, errors.Wrapf(
+                    err,
+                    "Error while building common table expression '%s'",
+                    cte.name,
+                )
+            }
+            queryStrings[i] = queryString
+            args = append(args, queryArgs...)
+        }
+
+        cteStrings = append(cteStrings, fmt.Sprintf(
+            "%s AS (%s)", cte.name, strings.Join(queryStrings, " UNION ALL "),
+        ))
+    }
+
+    return fmt.Sprintf(
+        "%s %s ", keyword, strings.Join(cteStrings, ", "),
+    ), args, nil
+}
@@ -0,0 +1,37 @@
+package dot
+
+import (
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// Count selects COUNT(expression) as alias.
+func Count(expression qtypes.Queryable, alias string) qtypes.Selectable {
+    return qtypes.CountSelectable(expression, alias)
+}
+
+// CountDistinct selects COUNT(DISTINCT expression) as alias.
+func CountDistinct(
+    expression qtypes.Queryable, alias string,
+) qtypes.Selectable {
+    return qtypes.CountDistinctSelectable(expression, alias)
+}
+
+// Sum selects SUM(expression) as alias.
+func Sum(expression qtypes.Queryable, alias string) qtypes.Selectable {
+    return qtypes.SumSelectable(expression, alias)
+}
+
+// Avg selects AVG(expression) as alias.
+func Avg(expression qtypes.Queryable, alias string) qtypes.Selectable {
+    return qtypes.AvgSelectable(expression, alias)
+}
+
+// Min selects MIN(expression) as alias.
+func Min(expression qtypes.Queryable, alias string) qtypes.Selectable {
+    return qtypes.MinSelectable(expression, alias)
+}
+
+// Max selects MAX(expression) as alias.
+func Max(expression qtypes.Queryable, alias string) qtypes.Selectable {
+    return qtypes.MaxSelectable(expression, alias)
+}
@@ -1,6 +1,8 @@
 package dot
 
 import (
+    "reflect"
+
     "github.com/daihasso/machgo/query/qtypes"
 )
 
@@ -21,14 +23,80 @@ func queryableFromInterface(
     return qtypes.NewDefaultCondition(lhs, rhs, combiner)
 }
 
+// isNil checks if the provided value will be sent to the database as NULL.
+func isNil(in interface{}) bool {
+    if in == nil {
+        return true
+    }
+    value := reflect.ValueOf(in)
+    return value.Kind() == reflect.Ptr && value.IsNil()
+}
+
+// Equal checks that lhs equals rhs. Comparing with nil checks for NULL
+// instead.
 func Equal(lhs, rhs interface{}) qtypes.Queryable {
+    if isNil(rhs) {
+        return IsNull(lhs)
+    } else if isNil(lhs) {
+        return IsNull(rhs)
+    }
     return queryableFromInterface(lhs, rhs, qtypes.EqualCombiner)
 }
 
+// NotEqual checks that lhs doesn't equal rhs. Comparing with nil checks for
+// NOT NULL instead.
 func NotEqual(lhs, rhs interface{}) qtypes.Queryable {
+    if isNil(rhs) {
+        return IsNotNull(lhs)
+    } else if isNil(lhs) {
+        return IsNotNull(rhs)
+    }
     return queryableFromInterface(lhs, rhs, qtypes.NotEqualCombiner)
 }
 
+// IsNull checks that the provided value is NULL.
+func IsNull(in interface{}) qtypes.Queryable {
+    return queryableFromInterface(
+        in, qtypes.NullQueryable{}, qtypes.IsCombiner,
+    )
+}
+
+// IsNotNull checks that the provided value isn't NULL.
+func IsNotNull(in interface{}) qtypes.Queryable {
+    return queryableFromInterface(
+        in, qtypes.NullQueryable{}, qtypes.IsNotCombiner,
+    )
+}
+
+// Like matches lhs against the pattern rhs.
+func Like(lhs, rhs interface{}) qtypes.Queryable {
+    return queryableFromInterface(lhs, rhs, qtypes.LikeCombiner)
+}
+
+// ILike matches lhs against the pattern rhs ignoring case. This is only
+// supported by postgres.
+func ILike(lhs, rhs interface{}) qtypes.Queryable {
+    return queryableFromInterface(lhs, rhs, qtypes.ILikeCombiner)
+}
+
+// Between checks that in is between low and high inclusively.
+func Between(in, low, high interface{}) qtypes.Queryable {
+    bounds := qtypes.MultiCondition{
+        Values: interfaceToQueryableMulti(low, high),
+        Combiner: qtypes.AndCombiner,
+    }
+    return queryableFromInterface(in, bounds, qtypes.BetweenCombiner)
+}
+
+// IsDistinctFrom checks that lhs doesn't equal rhs treating NULL as a
+// comparable value. This is only supported by postgres.
+func IsDistinctFrom(lhs, rhs interface{}) qtypes.Queryable {
+    if isNil(rhs) {
+        rhs = qtypes.NullQueryable{}
+    }
+    return queryableFromInterface(lhs, rhs, qtypes.IsDistinctFromCombiner)
+}
+
 func GreaterThan(lhs, rhs interface{}) qtypes.Queryable {
     return queryableFromInterface(lhs, rhs, qtypes.GreaterThanCombiner)
 }
@@ -55,6 +123,30 @@ func In(lhs interface{}, rhs ...interface{}) qtypes.Queryable {
     )
 }
 
+// Exists checks that the provided subquery returns any rows.
+func Exists(subquery qtypes.Queryable) qtypes.Queryable {
+    return qtypes.ExistsCondition{
+        Value: subquery,
+    }
+}
+
+// NotExists checks that the provided subquery doesn't return any rows.
+func NotExists(subquery qtypes.Queryable) qtypes.Queryable {
+    return qtypes.NotCondition{
+        Value: Exists(subquery),
+    }
+}
+
+func NotIn(lhs interface{}, rhs ...interface{}) qtypes.Queryable {
+    lhsQueryable := qtypes.InterfaceToQueryable(lhs)
+    queryableRHS := interfaceToQueryableMulti(rhs...)
+    rhsQueryable := qtypes.NewMultiListCondition(queryableRHS...)
+
+    return qtypes.NewDefaultCondition(
+        lhsQueryable, rhsQueryable, qtypes.NotInCombiner,
+    )
+}
+
 func And(ins ...interface{}) qtypes.Queryable {
     insQueryable := interfaceToQueryableMulti(ins...)
 
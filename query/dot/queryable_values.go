@@ -22,3 +22,24 @@ func ObjectColumn(obj base.Base, column string) qtypes.Queryable {
 func SelectObject(obj base.Base) qtypes.Selectable {
     return qtypes.BaseSelectable(obj)
 }
+
+func AliasColumn(alias, column string) qtypes.Queryable {
+    return qtypes.AliasColumn(alias, column)
+}
+
+// Raw creates a raw SQL fragment replacing each `?` in sql with the matching
+// argument. Columns and other Queryables are rendered in place and any other
+// value becomes a bind argument. Write `??` for a literal `?` such as the
+// Postgres jsonb `?` and `?|` operators; a `?` inside a quoted string or
+// identifier is always literal.
+func Raw(sql string, args ...interface{}) qtypes.Queryable {
+    return qtypes.RawQueryable{
+        SQL: sql,
+        Args: args,
+    }
+}
+
+// SelectAs selects the provided expression as alias.
+func SelectAs(expression qtypes.Queryable, alias string) qtypes.Selectable {
+    return qtypes.ExpressionSelectable(expression, alias)
+}
@@ -0,0 +1,25 @@
+package dot
+
+import (
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// Over evaluates the provided function (such as an aggregate) over a window.
+func Over(function qtypes.Queryable) qtypes.WindowExpression {
+    return qtypes.Over(function)
+}
+
+// RowNumber numbers the rows of each window starting at 1.
+func RowNumber() qtypes.WindowExpression {
+    return qtypes.RowNumber()
+}
+
+// Rank ranks the rows of each window leaving gaps after ties.
+func Rank() qtypes.WindowExpression {
+    return qtypes.Rank()
+}
+
+// DenseRank ranks the rows of each window without leaving gaps after ties.
+func DenseRank() qtypes.WindowExpression {
+    return qtypes.DenseRank()
+}
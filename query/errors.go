@@ -0,0 +1,22 @@
+package query
+
+import (
+    "github.com/pkg/errors"
+)
+
+// AmbiguousRelationshipError is returned when two joined objects have more
+// than one relationship between them and none was picked with JoinVia.
+var AmbiguousRelationshipError = errors.New(
+    "Objects have multiple relationships between them, use JoinVia to " +
+        "pick one",
+)
+
+// NotFoundError is returned when a query which should find a result has no
+// results.
+var NotFoundError = errors.New("No results found for query")
+
+// MultipleResultsError is returned when a query which should find a single
+// result has more than one.
+var MultipleResultsError = errors.New(
+    "Query found multiple results but only one was expected",
+)
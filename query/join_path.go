@@ -0,0 +1,206 @@
+package query
+
+import (
+    "sync"
+
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/base"
+)
+
+// relationshipGraph is an undirected graph of tables connected by the
+// relationships declared between them.
+type relationshipGraph struct {
+    // neighbors maps a table to the relationships leading out of it, each
+    // oriented so that the table is on the self side.
+    neighbors map[string][]*base.Relationship
+}
+
+func (self *relationshipGraph) add(relationship base.Relationship) {
+    selfTable, targetTable := relationship.Tables()
+    if selfTable == targetTable {
+        // Self-referencing relationships never lead anywhere new.
+        return
+    }
+
+    forward := relationship
+    self.neighbors[selfTable] = append(self.neighbors[selfTable], &forward)
+    self.neighbors[targetTable] = append(
+        self.neighbors[targetTable], relationship.Invert(),
+    )
+}
+
+// hop finds the relationship leading from one table to another erroring if
+// there's more than one way to do so.
+func (self *relationshipGraph) hop(
+    fromTable, toTable string,
+) (*base.Relationship, error) {
+    var match *base.Relationship
+    for _, relationship := range self.neighbors[fromTable] {
+        _, targetTable := relationship.Tables()
+        if targetTable != toTable {
+            continue
+        }
+        if match != nil {
+            matchFrom, matchTo := match.Columns()
+            relFrom, relTo := relationship.Columns()
+            if matchFrom != relFrom || matchTo != relTo {
+                return nil, errors.Wrapf(
+                    AmbiguousRelationshipError,
+                    "Found several relationships between '%s' and '%s'",
+                    fromTable,
+                    toTable,
+                )
+            }
+            continue
+        }
+        match = relationship
+    }
+
+    return match, nil
+}
+
+// neighborTables lists the distinct tables directly related to the provided
+// table in the order their relationships were added.
+func (self *relationshipGraph) neighborTables(table string) []string {
+    var tables []string
+    seen := make(map[string]bool)
+    for _, relationship := range self.neighbors[table] {
+        _, targetTable := relationship.Tables()
+        if seen[targetTable] {
+            continue
+        }
+        seen[targetTable] = true
+        tables = append(tables, targetTable)
+    }
+
+    return tables
+}
+
+// shortestPath finds the shortest series of relationships leading from the
+// provided table to any of the target tables using a breadth first search.
+// It errors if there are several equally short paths since there'd be no
+// way to tell which was meant.
+func (self *relationshipGraph) shortestPath(
+    fromTable string, targetTables map[string]bool,
+) ([]*base.Relationship, error) {
+    depths := map[string]int{fromTable: 0}
+    pathCounts := map[string]int{fromTable: 1}
+    previous := map[string]string{fromTable: ""}
+    frontier := []string{fromTable}
+    var found []string
+    for len(frontier) > 0 && len(found) == 0 {
+        var next []string
+        for _, table := range frontier {
+            for _, targetTable := range self.neighborTables(table) {
+                depth, ok := depths[targetTable]
+                if !ok {
+                    depths[targetTable] = depths[table] + 1
+                    pathCounts[targetTable] = pathCounts[table]
+                    previous[targetTable] = table
+                    next = append(next, targetTable)
+                } else if depth == depths[table] + 1 {
+                    pathCounts[targetTable] += pathCounts[table]
+                }
+            }
+        }
+        for _, table := range next {
+            if targetTables[table] {
+                found = append(found, table)
+            }
+        }
+        frontier = next
+    }
+
+    if len(found) == 0 {
+        return nil, errors.Errorf(
+            "No path of relationships leads from '%s' to the query",
+            fromTable,
+        )
+    }
+    if len(found) > 1 || pathCounts[found[0]] > 1 {
+        return nil, errors.Wrapf(
+            AmbiguousRelationshipError,
+            "Found several paths of relationships of the same length " +
+                "from '%s' to the query",
+            fromTable,
+        )
+    }
+
+    var tables []string
+    for table := found[0]; table != ""; table = previous[table] {
+        tables = append([]string{table}, tables...)
+    }
+
+    path := make([]*base.Relationship, 0, len(tables) - 1)
+    for i := 0; i < len(tables) - 1; i++ {
+        relationship, err := self.hop(tables[i], tables[i+1])
+        if err != nil {
+            return nil, err
+        }
+        path = append(path, relationship)
+    }
+
+    return path, nil
+}
+
+// copy creates a copy of the graph which can be added to without changing
+// the original.
+func (self *relationshipGraph) copy() *relationshipGraph {
+    graph := &relationshipGraph{
+        neighbors: make(
+            map[string][]*base.Relationship, len(self.neighbors),
+        ),
+    }
+    for table, relationships := range self.neighbors {
+        graph.neighbors[table] = append(
+            []*base.Relationship(nil), relationships...,
+        )
+    }
+
+    return graph
+}
+
+var linkGraphMutex sync.Mutex
+var linkGraph *relationshipGraph
+var linkGraphVersion uint64
+
+// cachedLinkGraph retrieves the graph of the relationships of the registered
+// link models, only rebuilding it when more have been registered.
+func cachedLinkGraph() *relationshipGraph {
+    linkGraphMutex.Lock()
+    defer linkGraphMutex.Unlock()
+
+    descriptors, version := base.LinkModels()
+    if linkGraph != nil && linkGraphVersion == version {
+        return linkGraph
+    }
+
+    graph := &relationshipGraph{
+        neighbors: make(map[string][]*base.Relationship),
+    }
+    for _, descriptor := range descriptors {
+        for _, relationship := range descriptor.Relationships() {
+            graph.add(relationship)
+        }
+    }
+    linkGraph = graph
+    linkGraphVersion = version
+
+    return linkGraph
+}
+
+// newRelationshipGraph builds a graph from the relationships of the provided
+// objects and the registered link models (see base.RegisterLinkModels).
+func newRelationshipGraph(objects []base.Base) *relationshipGraph {
+    graph := cachedLinkGraph().copy()
+    for _, object := range objects {
+        if relationshipable, ok := object.(base.Relationshipable); ok {
+            for _, relationship := range relationshipable.Relationships() {
+                graph.add(relationship)
+            }
+        }
+    }
+
+    return graph
+}
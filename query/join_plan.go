@@ -0,0 +1,139 @@
+package query
+
+import (
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/base"
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// joinEdge is a relationship between two specific aliases in a query.
+type joinEdge struct {
+    relationship *base.Relationship
+    fromAlias,
+    toAlias string
+}
+
+func (self joinEdge) invert() *joinEdge {
+    return &joinEdge{
+        relationship: self.relationship.Invert(),
+        fromAlias: self.toAlias,
+        toAlias: self.fromAlias,
+    }
+}
+
+// joinRoot determines which alias the FROM clause should start with. This is
+// normally the first alias of the first relationship but an outer joined
+// table can't be the root without inverting the meaning of the join.
+func (self Query) joinRoot(joinEdges []*joinEdge) string {
+    rootAlias := joinEdges[0].fromAlias
+    if !self.joinTypes[rootAlias].Outer() {
+        return rootAlias
+    }
+
+    for _, object := range self.relationshipJoinedObjects() {
+        alias, err := self.Tables.ObjectAlias(object)
+        if err != nil || self.joinTypes[alias].Outer() {
+            continue
+        }
+        return alias
+    }
+
+    return rootAlias
+}
+
+// orderJoins orders (and inverts where needed) the provided edges so that
+// each one joins a new alias onto aliases that are already included.
+func orderJoins(
+    rootAlias string, joinEdges []*joinEdge,
+) ([]*joinEdge, error) {
+    included := map[string]bool{rootAlias: true}
+    remaining := joinEdges
+    ordered := make([]*joinEdge, 0, len(joinEdges))
+    for len(remaining) > 0 {
+        var skipped []*joinEdge
+        for _, edge := range remaining {
+            switch {
+            case included[edge.fromAlias] && !included[edge.toAlias]:
+                ordered = append(ordered, edge)
+                included[edge.toAlias] = true
+            case included[edge.toAlias] && !included[edge.fromAlias]:
+                ordered = append(ordered, edge.invert())
+                included[edge.fromAlias] = true
+            case included[edge.toAlias] && included[edge.fromAlias]:
+                // Both sides are already joined; nothing new to add.
+            default:
+                skipped = append(skipped, edge)
+            }
+        }
+
+        if len(skipped) == len(remaining) {
+            return nil, errors.New(
+                "Objects joined don't all connect to each other",
+            )
+        }
+        remaining = skipped
+    }
+
+    return ordered, nil
+}
+
+// markNullableAliases flags the aliases which might be entirely missing from
+// a row because of an outer join.
+func (self Query) markNullableAliases(joinEdges []*joinEdge) {
+    var includedAliases []string
+    for i, edge := range joinEdges {
+        if i == 0 {
+            includedAliases = append(includedAliases, edge.fromAlias)
+        }
+        joinType := self.joinTypes[edge.toAlias]
+
+        switch joinType {
+        case qtypes.LeftJoinType, qtypes.FullJoinType:
+            self.Tables.SetAliasNullable(edge.toAlias, true)
+        }
+        switch joinType {
+        case qtypes.RightJoinType, qtypes.FullJoinType:
+            for _, alias := range includedAliases {
+                self.Tables.SetAliasNullable(alias, true)
+            }
+        }
+
+        includedAliases = append(includedAliases, edge.toAlias)
+    }
+}
+
+// planJoins works out the relationships needed to join all the objects in the
+// query in the order they should be joined.
+func (self Query) planJoins() ([]*joinEdge, error) {
+    joinEdges, err := self.solveJoin(self.joinedIntermediateAlias)
+    if err != nil {
+        return nil, err
+    }
+    if len(joinEdges) == 0 {
+        return nil, errors.New(
+            "Objects joined don't have relationships with each other",
+        )
+    }
+    connected := make(map[string]bool, len(joinEdges) + 1)
+    for _, edge := range joinEdges {
+        connected[edge.fromAlias] = true
+        connected[edge.toAlias] = true
+    }
+    for _, object := range self.relationshipJoinedObjects() {
+        alias, err := self.Tables.ObjectAlias(object)
+        if err != nil || !connected[alias] {
+            return nil, errors.New(
+                "Objects joined don't all connect to each other",
+            )
+        }
+    }
+
+    orderedEdges, err := orderJoins(self.joinRoot(joinEdges), joinEdges)
+    if err != nil {
+        return nil, err
+    }
+    self.markNullableAliases(orderedEdges)
+
+    return orderedEdges, nil
+}
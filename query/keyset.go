@@ -0,0 +1,352 @@
+package query
+
+import (
+    "database/sql/driver"
+    "fmt"
+    "reflect"
+
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// keysetCursor is the position in the query's ordering that a page of
+// results starts after (or ends before).
+type keysetCursor struct {
+    values []interface{}
+    before bool
+}
+
+// orderingColumn is a single statement in the query's ordering.
+type orderingColumn struct {
+    statement qtypes.Queryable
+    descending bool
+}
+
+func (self *Query) setKeysetCursor(cursor string, before bool) *Query {
+    self.cached.Where.invalidate()
+    self.cached.Options.invalidate()
+
+    values, err := qtypes.DecodeCursor(cursor)
+    if err != nil {
+        self.Errors = append(self.Errors, errors.Wrap(
+            err, "Error while reading keyset cursor",
+        ))
+        return self
+    }
+
+    self.keyset = &keysetCursor{
+        values: values,
+        before: before,
+    }
+
+    return self
+}
+
+// After limits the query to the rows which come after the row the provided
+// cursor was read from in the query's ordering. Cursors are retrieved with
+// QueryResults.Cursor and the query must be ordered the same way as the
+// query the cursor came from. The ordering should be unique (ending with a
+// primary key for example) and its columns must be selected and not NULL.
+func (self *Query) After(cursor string) *Query {
+    return self.setKeysetCursor(cursor, false)
+}
+
+// Before limits the query to the rows which come before the row the provided
+// cursor was read from in the query's ordering. Rows are still returned in
+// the query's ordering so with a Limit this returns the page of rows just
+// before the cursor.
+func (self *Query) Before(cursor string) *Query {
+    return self.setKeysetCursor(cursor, true)
+}
+
+// orderingColumns retrieves each of the statements the query is ordered by.
+func (self Query) orderingColumns() []orderingColumn {
+    var columns []orderingColumn
+    var addStatement func(statement qtypes.Queryable, descending bool)
+    addStatement = func(statement qtypes.Queryable, descending bool) {
+        switch typedStatement := statement.(type) {
+        case qtypes.MultiCondition:
+            if typedStatement.Combiner == qtypes.CommaCombiner {
+                for _, value := range typedStatement.Values {
+                    addStatement(value, descending)
+                }
+                return
+            }
+        case qtypes.AscendingQueryable:
+            addStatement(typedStatement.Statement, false)
+            return
+        case qtypes.DescendingQueryable:
+            addStatement(typedStatement.Statement, true)
+            return
+        }
+
+        columns = append(columns, orderingColumn{
+            statement: statement,
+            descending: descending,
+        })
+    }
+
+    for _, option := range self.OptionClauses {
+        if orderOption, ok := option.(qtypes.OrderByOption); ok {
+            addStatement(orderOption.Order, false)
+        }
+    }
+
+    return columns
+}
+
+// orderingColumnNames works out the names of the selected columns which
+// match the query's ordering.
+func (self Query) orderingColumnNames(
+    columns []orderingColumn,
+) ([]string, map[string]string, error) {
+    selected, _, err := self.selectColumns()
+    if err != nil {
+        return nil, nil, err
+    }
+    names := make(map[string]string, len(selected))
+    for _, column := range selected {
+        if column.name != "" {
+            names[column.expression] = column.name
+        }
+    }
+
+    orderingNames := make([]string, len(columns))
+    for i, column := range columns {
+        expression, _ := column.statement.QueryValue(self.Tables)
+        name, ok := names[expression]
+        if !ok {
+            return nil, nil, errors.Errorf(
+                "Ordering column '%s' must be selected for keyset " +
+                    "pagination",
+                expression,
+            )
+        }
+        orderingNames[i] = name
+    }
+
+    return orderingNames, names, nil
+}
+
+// cursorColumns retrieves the names of the result columns that make up a
+// cursor for this query if it has one.
+func (self Query) cursorColumns() ([]string, bool) {
+    columns := self.orderingColumns()
+    if len(columns) == 0 {
+        return nil, false
+    }
+    names, _, err := self.orderingColumnNames(columns)
+    if err != nil {
+        return nil, false
+    }
+
+    return names, true
+}
+
+// checkCursorValues checks that each of the cursor's values has the type the
+// column it's compared with is written as. Cursors come from clients so this
+// refuses values which have been tampered with or which came from a query
+// ordered by other columns.
+func (self Query) checkCursorValues(
+    columns []orderingColumn, values []interface{},
+) error {
+    selected, _, err := self.selectColumns()
+    if err != nil {
+        return err
+    }
+    valueTypes := make(map[string]reflect.Type, len(selected))
+    for _, column := range selected {
+        if column.valueType != nil {
+            valueTypes[column.expression] = column.valueType
+        }
+    }
+
+    for i, column := range columns {
+        expression, _ := column.statement.QueryValue(self.Tables)
+        valueType, ok := valueTypes[expression]
+        if !ok {
+            continue
+        }
+        // The cursor holds driver values so compare against the driver value
+        // of the column's type; types without one can't be checked.
+        expected, err := driver.DefaultParameterConverter.ConvertValue(
+            reflect.Zero(valueType).Interface(),
+        )
+        if err != nil || expected == nil {
+            continue
+        }
+        if reflect.TypeOf(values[i]) != reflect.TypeOf(expected) {
+            return errors.Errorf(
+                "Cursor value #%d for '%s' should be %T but is %T",
+                i,
+                expression,
+                expected,
+                values[i],
+            )
+        }
+    }
+
+    return nil
+}
+
+// groupedCondition wraps a condition in parenthesis so that it's combined
+// with others as a whole.
+type groupedCondition struct {
+    condition qtypes.Queryable
+}
+
+func (self groupedCondition) String() string {
+    return fmt.Sprintf("(%s)", self.condition.String())
+}
+
+func (self groupedCondition) QueryValue(
+    at *qtypes.AliasedTables,
+) (string, []interface{}) {
+    queryString, args := self.condition.QueryValue(at)
+    return fmt.Sprintf("(%s)", queryString), args
+}
+
+// keysetCondition builds a condition which matches the rows after (or
+// before) the provided values in the provided ordering. Row value
+// comparisons can't be used because the columns can be ordered in different
+// directions so each column is compared in turn:
+//   (a > 1) OR (a = 1 AND b < 2) OR ...
+func keysetCondition(
+    columns []orderingColumn, values []interface{}, before bool,
+) qtypes.Queryable {
+    alternatives := make([]qtypes.Queryable, len(columns))
+    for i, column := range columns {
+        conditions := make([]qtypes.Queryable, 0, i+1)
+        for j := 0; j < i; j++ {
+            conditions = append(conditions, qtypes.NewDefaultCondition(
+                columns[j].statement,
+                qtypes.ConstantQueryable{Values: values[j:j+1]},
+                qtypes.EqualCombiner,
+            ))
+        }
+
+        combiner := qtypes.GreaterThanCombiner
+        if column.descending != before {
+            combiner = qtypes.LessThanCombiner
+        }
+        conditions = append(conditions, qtypes.NewDefaultCondition(
+            column.statement,
+            qtypes.ConstantQueryable{Values: values[i:i+1]},
+            combiner,
+        ))
+
+        if len(conditions) == 1 {
+            alternatives[i] = conditions[0]
+            continue
+        }
+        alternatives[i] = groupedCondition{
+            condition: qtypes.NewMultiAndCondition(conditions...),
+        }
+    }
+
+    return groupedCondition{
+        condition: qtypes.NewMultiOrCondition(alternatives...),
+    }
+}
+
+// reversedOrdering builds the query's options with the ordering reversed.
+func (self Query) reversedOrdering(
+    columns []orderingColumn,
+) []qtypes.QueryOption {
+    statements := make([]qtypes.Queryable, len(columns))
+    for i, column := range columns {
+        if column.descending {
+            statements[i] = qtypes.AscendingQueryable{
+                Statement: column.statement,
+            }
+        } else {
+            statements[i] = qtypes.DescendingQueryable{
+                Statement: column.statement,
+            }
+        }
+    }
+
+    options := make([]qtypes.QueryOption, 0, len(self.OptionClauses))
+    for _, option := range self.OptionClauses {
+        if option.OptionType() == qtypes.OrderByOptionType {
+            option = qtypes.OrderByOption{
+                Order: qtypes.NewMultiListCondition(statements...),
+            }
+        }
+        options = append(options, option)
+    }
+
+    return options
+}
+
+// buildKeysetQuery builds the query limited to the rows after or before its
+// cursor. Rows before the cursor are found by reversing the ordering so that
+// the rows closest to the cursor are used; they are then put back in the
+// query's ordering.
+func (self Query) buildKeysetQuery() (string, []interface{}, error) {
+    if len(self.setOperations) != 0 {
+        return "", nil, errors.New(
+            "Keyset pagination can't be combined with set operations",
+        )
+    }
+    columns := self.orderingColumns()
+    if len(columns) == 0 {
+        return "", nil, errors.New(
+            "Keyset pagination requires the query to be ordered",
+        )
+    }
+    if len(columns) != len(self.keyset.values) {
+        return "", nil, errors.Errorf(
+            "Cursor has %d values but the query is ordered by %d columns",
+            len(self.keyset.values),
+            len(columns),
+        )
+    }
+    err := self.checkCursorValues(columns, self.keyset.values)
+    if err != nil {
+        return "", nil, err
+    }
+    _, names, err := self.orderingColumnNames(columns)
+    if err != nil {
+        return "", nil, err
+    }
+
+    page := self
+    page.keyset = nil
+    page.cached = cachedQuery{}
+    page.WhereClauses = append(
+        append([]qtypes.Queryable(nil), self.WhereClauses...),
+        keysetCondition(columns, self.keyset.values, self.keyset.before),
+    )
+    if !self.keyset.before {
+        return page.buildQuery()
+    }
+
+    page.OptionClauses = self.reversedOrdering(columns)
+    pageQuery, args, err := page.buildQuery()
+    if err != nil {
+        return "", nil, err
+    }
+
+    var orderStatements []qtypes.Queryable
+    for _, option := range self.OptionClauses {
+        if orderOption, ok := option.(qtypes.OrderByOption); ok {
+            orderStatements = append(orderStatements, orderOption.Order)
+        }
+    }
+    orderOption := qtypes.OrderByOption{
+        Order: outputColumns(
+            qtypes.NewMultiListCondition(orderStatements...), names,
+        ),
+    }
+    orderQuery, orderArgs := orderOption.QueryValue(self.Tables)
+    args = append(args, orderArgs...)
+
+    // #nosec G201
+    query := fmt.Sprintf(
+        "SELECT * FROM (%s) keyset_page %s", pageQuery, orderQuery,
+    )
+
+    return query, args, nil
+}
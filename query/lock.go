@@ -0,0 +1,181 @@
+package query
+
+import (
+    "sort"
+
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/base"
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// setLockOption adds a lock with the provided strength to the query or
+// changes the strength of the existing one.
+func (self *Query) setLockOption(strength qtypes.LockStrength) *Query {
+    self.cached.Options.invalidate()
+
+    for i, optionClause := range self.OptionClauses {
+        if lockOption, ok := optionClause.(qtypes.LockOption); ok {
+            lockOption.Strength = strength
+            self.OptionClauses[i] = lockOption
+            return self
+        }
+    }
+
+    self.OptionClauses = append(self.OptionClauses, qtypes.LockOption{
+        Strength: strength,
+    })
+
+    return self
+}
+
+// updateLockOption changes the query's existing lock.
+func (self *Query) updateLockOption(
+    name string, update func(*qtypes.LockOption),
+) *Query {
+    self.cached.Options.invalidate()
+
+    for i, optionClause := range self.OptionClauses {
+        if lockOption, ok := optionClause.(qtypes.LockOption); ok {
+            update(&lockOption)
+            self.OptionClauses[i] = lockOption
+            return self
+        }
+    }
+
+    self.Errors = append(self.Errors, errors.Errorf(
+        "%s requires a lock, use ForUpdate or ForShare first", name,
+    ))
+
+    return self
+}
+
+// locksRows checks if the query locks the rows it reads.
+func (self Query) locksRows() bool {
+    _, ok := self.lockOption()
+    return ok
+}
+
+// lockOption retrieves the query's lock if it has one.
+func (self Query) lockOption() (qtypes.LockOption, bool) {
+    for _, optionClause := range self.OptionClauses {
+        if lockOption, ok := optionClause.(qtypes.LockOption); ok {
+            return lockOption, true
+        }
+    }
+
+    return qtypes.LockOption{}, false
+}
+
+// lockRefusal describes why the rows of the query can't be locked if they
+// can't be regardless of which tables are locked.
+func (self Query) lockRefusal() string {
+    if len(self.setOperations) != 0 {
+        return "set operations"
+    }
+    if self.distinct || len(self.distinctOn) != 0 {
+        return "DISTINCT"
+    }
+    for _, optionClause := range self.OptionClauses {
+        switch optionClause.OptionType() {
+        case qtypes.GroupByOptionType, qtypes.HavingOptionType:
+            return "GROUP BY or HAVING"
+        }
+    }
+    for _, selectExp := range self.SelectExpressions {
+        expression, ok := selectExp.Expression()
+        if !ok {
+            continue
+        }
+        switch expression.(type) {
+        case qtypes.SelectFunction:
+            return "aggregate functions"
+        case qtypes.WindowExpression:
+            return "window functions"
+        }
+    }
+
+    return ""
+}
+
+// checkLock checks that the database can lock the rows the query reads. The
+// rows of a table on the nullable side of an outer join can't be locked so
+// such tables have to be left out with Of. This relies on the from clause
+// having been built so that the nullable tables are known.
+func (self Query) checkLock() error {
+    lock, ok := self.lockOption()
+    if !ok {
+        return nil
+    }
+    if refusal := self.lockRefusal(); refusal != "" {
+        return errors.Errorf(
+            "Rows can't be locked in a query with %s", refusal,
+        )
+    }
+
+    aliases := self.Tables.Aliases()
+    if len(lock.Of) != 0 {
+        aliases = make([]string, 0, len(lock.Of))
+        for _, object := range lock.Of {
+            alias, err := self.Tables.ObjectAlias(object)
+            if err != nil {
+                return errors.Wrap(err, "Error while finding table to lock")
+            }
+            aliases = append(aliases, alias)
+        }
+    }
+    sort.Strings(aliases)
+    for _, alias := range aliases {
+        if self.Tables.AliasNullable(alias) {
+            return errors.Errorf(
+                "Rows of '%s' can't be locked since it's on the nullable " +
+                    "side of an outer join, use Of to lock other tables",
+                alias,
+            )
+        }
+    }
+
+    return nil
+}
+
+// ForUpdate locks the rows read by the query against updates and other locks
+// until the transaction ends. The query has to be run in the caller's
+// transaction with InTx so that the lock is held until the caller finishes
+// with the rows.
+func (self *Query) ForUpdate() *Query {
+    return self.setLockOption(qtypes.ForUpdateLockStrength)
+}
+
+// ForShare locks the rows read by the query against updates until the
+// transaction ends while still allowing other shared locks. Like ForUpdate
+// the query has to be run with InTx.
+func (self *Query) ForShare() *Query {
+    return self.setLockOption(qtypes.ForShareLockStrength)
+}
+
+// NoWait makes the query's lock fail immediately if any of the rows are
+// already locked instead of waiting for them.
+func (self *Query) NoWait() *Query {
+    return self.updateLockOption("NoWait", func(lock *qtypes.LockOption) {
+        lock.Wait = qtypes.NoWaitLockWait
+    })
+}
+
+// SkipLocked makes the query leave out any rows which are already locked
+// instead of waiting for them.
+func (self *Query) SkipLocked() *Query {
+    return self.updateLockOption(
+        "SkipLocked",
+        func(lock *qtypes.LockOption) {
+            lock.Wait = qtypes.SkipLockedLockWait
+        },
+    )
+}
+
+// Of limits the query's lock to the rows of the provided objects (which
+// should be in the query) rather than every table in the query.
+func (self *Query) Of(objects ...base.Base) *Query {
+    return self.updateLockOption("Of", func(lock *qtypes.LockOption) {
+        lock.Of = append(lock.Of, objects...)
+    })
+}
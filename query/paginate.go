@@ -0,0 +1,151 @@
+package query
+
+import (
+    "context"
+    "database/sql"
+
+    "github.com/jmoiron/sqlx"
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// PageInfo describes a page of results retrieved with Paginate.
+type PageInfo struct {
+    Page,
+    PerPage,
+    Total,
+    Pages int
+    HasNext,
+    HasPrev bool
+}
+
+func newPageInfo(page, perPage, total int) PageInfo {
+    pages := total / perPage
+    if total % perPage != 0 {
+        pages++
+    }
+
+    return PageInfo{
+        Page: page,
+        PerPage: perPage,
+        Total: total,
+        Pages: pages,
+        HasNext: page < pages,
+        HasPrev: page > 1,
+    }
+}
+
+// Paginate writes the provided (1-indexed) page of results to the provided
+// slices in the same fashion as QueryResults.WriteAllTo and returns
+// information about the page including the total count of results. The count
+// and the page are read in a single read-only, repeatable read transaction so
+// they are read from the same snapshot and are consistent with each other.
+// A transaction provided with InTx is used as is and left open. Rows can't be
+// locked while paginating.
+func (self Query) Paginate(
+    page, perPage int, objectSlices ...qtypes.BaseSlicePointer,
+) (pageInfo PageInfo, err error) {
+    if page < 1 {
+        return PageInfo{}, errors.Errorf(
+            "Page must be 1 or greater not %d", page,
+        )
+    }
+    if perPage < 1 {
+        return PageInfo{}, errors.Errorf(
+            "Items per page must be 1 or greater not %d", perPage,
+        )
+    }
+    if len(self.Errors) != 0 {
+        return PageInfo{}, errors.Errorf(
+            "Errors while forming query:\n%#+v",
+            self.Errors,
+        )
+    }
+    if self.locksRows() {
+        return PageInfo{}, errors.New("Rows can't be locked while paginating")
+    }
+
+    countQuery, countArgs, err := self.buildCountQuery()
+    if err != nil {
+        return PageInfo{}, errors.Wrap(
+            err, "Error while building count query",
+        )
+    }
+
+    pageQuery := self
+    pageQuery.OptionClauses = append(
+        []qtypes.QueryOption(nil), self.OptionClauses...,
+    )
+    pageQuery.Limit(perPage).Offset((page - 1) * perPage)
+    query, args, err := pageQuery.buildQuery()
+    if err != nil {
+        return PageInfo{}, errors.Wrap(err, "Error while building query")
+    }
+
+    // ownTx is only set when the transaction is started here; the caller's
+    // transaction is left for them to finish.
+    tx := self.tx
+    var ownTx *sqlx.Tx
+    if tx == nil {
+        tx, err = self.Pool.BeginTxx(
+            context.Background(),
+            &sql.TxOptions{
+                Isolation: sql.LevelRepeatableRead,
+                ReadOnly: true,
+            },
+        )
+        if err != nil {
+            return PageInfo{}, err
+        }
+        ownTx = tx
+    }
+
+    rows, err := self.queryInTx(tx, countQuery, countArgs)
+    if err != nil {
+        return PageInfo{}, rollbackFor(ownTx, err)
+    }
+    total := 0
+    if rows.Next() {
+        err = rows.Scan(&total)
+    }
+    if err == nil {
+        err = rows.Err()
+    }
+    rows.Close()
+    if err != nil {
+        return PageInfo{}, rollbackFor(ownTx, err)
+    }
+    pageInfo = newPageInfo(page, perPage, total)
+
+    rows, err = self.queryInTx(tx, query, args)
+    if err != nil {
+        return PageInfo{}, rollbackFor(ownTx, err)
+    }
+
+    results := pageQuery.newResults(ownTx, rows)
+    err = results.WriteAllTo(objectSlices...)
+    if err != nil {
+        return PageInfo{}, err
+    }
+
+    return pageInfo, nil
+}
+
+// rollbackFor rolls back the provided transaction in response to the
+// provided error. A nil transaction belongs to the caller and is left open.
+func rollbackFor(tx *sqlx.Tx, err error) error {
+    if tx == nil {
+        return err
+    }
+    newErr := tx.Rollback()
+    if newErr != nil {
+        return errors.Wrapf(
+            newErr,
+            "Error rolling back transaction in response to '%s'",
+            err.Error(),
+        )
+    }
+
+    return err
+}
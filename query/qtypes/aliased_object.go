@@ -0,0 +1,53 @@
+package qtypes
+
+import (
+    "regexp"
+    "strings"
+
+    "github.com/daihasso/machgo/base"
+)
+
+var explicitAliasRegex = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// AliasedObject pairs an object with an explicit alias. This allows the same
+// table to be included in a query more than once (for example a self-join).
+// It can be used anywhere an object is used in a query as well as with a
+// pointer to a slice when writing results.
+//
+// If Table is set the object is read from that table (such as a common table
+// expression) instead of its own. If Alias is empty one is generated. Aliases
+// are written unquoted so they must be lowercase to match the names the
+// database returns.
+type AliasedObject struct {
+    Object base.Base
+    Alias string
+    Table string
+}
+
+// Aliased creates an AliasedObject for the provided object and alias. The
+// alias is lowercased since the database folds unquoted aliases to lowercase.
+func Aliased(object base.Base, alias string) *AliasedObject {
+    return &AliasedObject{
+        Object: object,
+        Alias: strings.ToLower(alias),
+    }
+}
+
+// InTable creates an AliasedObject for the provided object read from the
+// provided table instead of its own.
+func InTable(object base.Base, table string) *AliasedObject {
+    return &AliasedObject{
+        Object: object,
+        Table: table,
+    }
+}
+
+// UnaliasedObject retrieves the underlying object for an AliasedObject or
+// the object itself if it isn't aliased.
+func UnaliasedObject(object base.Base) base.Base {
+    if aliasedObject, ok := object.(*AliasedObject); ok {
+        return aliasedObject.Object
+    }
+
+    return object
+}
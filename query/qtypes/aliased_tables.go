@@ -18,10 +18,22 @@ const tableAliasAlphabet = "abcdefghijklmnopqrstuvwxyz"+
 // alias mappings easier.
 type AliasedTables struct {
     aliasTable map[string]string
+    aliasType map[string]*reflect.Type
     tableAlias map[string]string
     tableType map[string]*reflect.Type
     typeTable map[reflect.Type]string
+    objectAlias map[base.Base]string
+    nullableAliases map[string]bool
+    hiddenAliases map[string]bool
+    explicitAliases map[string]bool
+    derivedAliases map[string]bool
     aliasCounter int
+
+    // parent is the AliasedTables of the query enclosing a subquery.
+    parent *AliasedTables
+    prefix string
+    subqueryPrefixes map[interface{}]string
+    reportedErrors []error
 }
 
 // Aliases returns all the aliases that this AliasedTables knows.
@@ -36,8 +48,7 @@ func (self AliasedTables) Aliases() []string {
 
 // TypeForAlias retrieves the type associated with the provided table alias.
 func (self AliasedTables) TypeForAlias(alias string) *reflect.Type {
-    tableName := self.aliasTable[alias]
-    return self.tableType[tableName]
+    return self.aliasType[alias]
 }
 
 // TypeForTable retrieves the type associated with the provided table name.
@@ -52,46 +63,277 @@ func (self AliasedTables) TableForAlias(alias string) string {
 }
 
 // AliasForTable retrieves the alias associated with the provided table name.
+// When a table is included more than once this is the first alias it was
+// given.
 func (self AliasedTables) AliasForTable(tableName string) (string, bool) {
     alias, ok := self.tableAlias[tableName]
+    if !ok && self.parent != nil {
+        return self.parent.AliasForTable(tableName)
+    }
     return alias, ok
 }
 
-// ObjectIsAliased checks if the provided object's type has been aliased in
-// this AliasedTables.
+// ObjectIsAliased checks if the provided object (or its type) has been
+// aliased in this AliasedTables.
 func (self AliasedTables) ObjectIsAliased(object base.Base) bool {
-    tableName, err := base.BaseTable(object)
-    if err != nil {
-        return false
-    }
-    _, ok := self.tableAlias[tableName]
-    return ok
+    _, err := self.ObjectAlias(object)
+    return err == nil
 }
 
-// ObjectAlias returns the alias asociated with this object's type.
+// ObjectAlias returns the alias asociated with this object. Objects that
+// were added (or that were wrapped with an explicit alias) resolve to their
+// own alias; any other object resolves to the first alias for its table.
+// Within a subquery objects of the enclosing query are resolved as well.
 func (self AliasedTables) ObjectAlias(object base.Base) (string, error) {
+    if alias, ok := self.exactObjectAlias(object); ok {
+        return alias, nil
+    }
+    if aliasedObject, ok := object.(*AliasedObject); ok {
+        if aliasedObject.Alias != "" {
+            return "", errors.Errorf(
+                "Alias '%s' is not included", aliasedObject.Alias,
+            )
+        }
+        if aliasedObject.Table != "" {
+            val, ok := self.AliasForTable(aliasedObject.Table)
+            if !ok {
+                return "", errors.Errorf(
+                    "Table '%s' is not included", aliasedObject.Table,
+                )
+            }
+            return val, nil
+        }
+        object = aliasedObject.Object
+    }
+
     tableName, err := base.BaseTable(object)
     if err != nil {
         return "", errors.New("Cannot determine name for object")
     }
-    val, ok := self.tableAlias[tableName]
+    val, ok := self.AliasForTable(tableName)
     if !ok {
         return "", errors.New("Provided Base is not aliased")
     }
     return val, nil
 }
 
+// exactObjectAlias finds the alias of an object that was explicitly aliased
+// or added itself.
+func (self AliasedTables) exactObjectAlias(object base.Base) (string, bool) {
+    aliasedObject, isAliased := object.(*AliasedObject)
+    if isAliased && aliasedObject.Alias != "" {
+        if _, ok := self.aliasTable[aliasedObject.Alias]; ok {
+            return aliasedObject.Alias, true
+        }
+    } else {
+        if isAliased {
+            object = aliasedObject.Object
+        }
+        if alias, ok := self.objectAlias[object]; ok {
+            return alias, true
+        }
+    }
+
+    if self.parent != nil {
+        return self.parent.exactObjectAlias(object)
+    }
+    return "", false
+}
+
 // TypeTable retrieves the table associate with the provided type.
 func (self AliasedTables) TypeTable(typ reflect.Type) string {
     return self.typeTable[typ]
 }
 
+// SetAliasNullable marks whether the table for the provided alias may be
+// entirely missing (all NULL) from a row; this happens with outer joins.
+func (self AliasedTables) SetAliasNullable(alias string, nullable bool) {
+    if nullable {
+        self.nullableAliases[alias] = true
+    } else {
+        delete(self.nullableAliases, alias)
+    }
+}
+
+// AliasNullable checks if the table for the provided alias may be entirely
+// missing (all NULL) from a row.
+func (self AliasedTables) AliasNullable(alias string) bool {
+    return self.nullableAliases[alias]
+}
+
+func (self *AliasedTables) nextAlias() (string, error) {
+    for self.aliasCounter < len(tableAliasAlphabet) {
+        alias := string(tableAliasAlphabet[self.aliasCounter])
+        self.aliasCounter++
+        if _, ok := self.aliasTable[alias]; !ok {
+            return alias, nil
+        }
+    }
+
+    // TODO: Make this account for doubled aliases like aa, ab, etc.
+    return "", errors.New(
+        "There's more objects in this query than we've accounted for.",
+    )
+}
+
+// SetAliasHidden marks whether the table for the provided alias should be left
+// out of the select by default; this is used for tables that were only
+// joined to connect other tables.
+func (self AliasedTables) SetAliasHidden(alias string, hidden bool) {
+    if hidden {
+        self.hiddenAliases[alias] = true
+    } else {
+        delete(self.hiddenAliases, alias)
+    }
+}
+
+// AliasHidden checks if the table for the provided alias should be left out
+// of the select by default.
+func (self AliasedTables) AliasHidden(alias string) bool {
+    return self.hiddenAliases[alias]
+}
+
+// AddDerivedTable reserves the provided alias for a derived table (a subquery
+// in the FROM clause) which has no table or type of its own.
+func (self *AliasedTables) AddDerivedTable(alias string) error {
+    if !explicitAliasRegex.MatchString(alias) {
+        return errors.Errorf(
+            "Alias '%s' must be lowercase alphanumeric and start with a " +
+                "letter",
+            alias,
+        )
+    }
+    if _, ok := self.aliasTable[alias]; ok {
+        return errors.Errorf("Alias '%s' is already in use", alias)
+    }
+
+    self.aliasTable[alias] = ""
+    self.explicitAliases[alias] = true
+    self.derivedAliases[alias] = true
+
+    return nil
+}
+
+// AliasDerived checks if the provided alias is for a derived table.
+func (self AliasedTables) AliasDerived(alias string) bool {
+    return self.derivedAliases[alias]
+}
+
+// SubqueryPrefix retrieves the prefix for the aliases of the subquery
+// identified by key when it's embedded in the query using these tables. The
+// same key always gets the same prefix.
+func (self AliasedTables) SubqueryPrefix(key interface{}) string {
+    if prefix, ok := self.subqueryPrefixes[key]; ok {
+        return prefix
+    }
+
+    prefix := fmt.Sprintf(
+        "%ss%d", self.prefix, len(self.subqueryPrefixes) + 1,
+    )
+    self.subqueryPrefixes[key] = prefix
+
+    return prefix
+}
+
+// Nested creates a copy of these tables for a subquery embedded in the query
+// using parent. Generated aliases are given the provided prefix so they can't
+// clash with the aliases of the enclosing query while explicit aliases are
+// kept as is. The returned function maps the original aliases to the new
+// ones.
+func (self AliasedTables) Nested(
+    parent *AliasedTables, prefix string,
+) (*AliasedTables, func(string) string) {
+    rename := func(alias string) string {
+        if self.explicitAliases[alias] {
+            return alias
+        }
+        return prefix + alias
+    }
+
+    nested := &AliasedTables{
+        aliasTable: make(map[string]string, len(self.aliasTable)),
+        aliasType: make(map[string]*reflect.Type, len(self.aliasType)),
+        tableAlias: make(map[string]string, len(self.tableAlias)),
+        tableType: self.tableType,
+        typeTable: self.typeTable,
+        objectAlias: make(map[base.Base]string, len(self.objectAlias)),
+        nullableAliases: make(map[string]bool),
+        hiddenAliases: make(map[string]bool),
+        explicitAliases: make(map[string]bool, len(self.explicitAliases)),
+        derivedAliases: make(map[string]bool, len(self.derivedAliases)),
+        aliasCounter: self.aliasCounter,
+        parent: parent,
+        prefix: prefix,
+        subqueryPrefixes: make(map[interface{}]string),
+    }
+    for alias, table := range self.aliasTable {
+        nested.aliasTable[rename(alias)] = table
+    }
+    for alias, typ := range self.aliasType {
+        nested.aliasType[rename(alias)] = typ
+    }
+    for table, alias := range self.tableAlias {
+        nested.tableAlias[table] = rename(alias)
+    }
+    for object, alias := range self.objectAlias {
+        nested.objectAlias[object] = rename(alias)
+    }
+    for alias := range self.nullableAliases {
+        nested.nullableAliases[rename(alias)] = true
+    }
+    for alias := range self.hiddenAliases {
+        nested.hiddenAliases[rename(alias)] = true
+    }
+    for alias := range self.explicitAliases {
+        nested.explicitAliases[alias] = true
+    }
+    for alias := range self.derivedAliases {
+        nested.derivedAliases[alias] = true
+    }
+
+    return nested, rename
+}
+
+// ReportError records an error found while rendering part of a query, such as
+// a subquery, where it can't be returned directly.
+func (self *AliasedTables) ReportError(err error) {
+    self.reportedErrors = append(self.reportedErrors, err)
+}
+
+// ReportedErrors retrieves the errors recorded with ReportError.
+func (self AliasedTables) ReportedErrors() []error {
+    return self.reportedErrors
+}
+
+// ClearReportedErrors forgets the errors recorded with ReportError.
+func (self *AliasedTables) ClearReportedErrors() {
+    self.reportedErrors = nil
+}
+
 // AddObjects adds the provided objects to the AliasedTables creating new
-// aliases and creating type and table mappings.
+// aliases and creating type and table mappings. Objects wrapped with Aliased
+// use their provided alias instead of a generated one.
 func (self *AliasedTables) AddObjects(objects ...base.Base) error {
     for _, object := range objects {
+        var alias, overrideTable string
+        if aliasedObject, ok := object.(*AliasedObject); ok {
+            alias = aliasedObject.Alias
+            overrideTable = aliasedObject.Table
+            if alias != "" && !explicitAliasRegex.MatchString(alias) {
+                return errors.Errorf(
+                    "Alias '%s' must be lowercase alphanumeric and start " +
+                        "with a letter",
+                    alias,
+                )
+            }
+            if _, ok := self.aliasTable[alias]; ok {
+                return errors.Errorf("Alias '%s' is already in use", alias)
+            }
+            object = aliasedObject.Object
+        }
+
         objType := reflect.TypeOf(object)
-        if objType.Kind() != reflect.Ptr {
+        if objType == nil || objType.Kind() != reflect.Ptr {
             return errors.Errorf(
                 "Object provided should be *%[1]T not %[1]T", object,
             )
@@ -104,27 +346,44 @@ func (self *AliasedTables) AddObjects(objects ...base.Base) error {
             )
         }
 
-        if self.aliasCounter > len(tableAliasAlphabet) {
-            // TODO: Make this account for doubled aliases like aa, ab, etc.
-            return errors.New(
-                "There's more objects in this query than we've accounted for.",
-            )
-        }
-
-        alias := string(tableAliasAlphabet[self.aliasCounter])
         tableName, err := base.BaseTable(object)
         if err != nil {
             return errors.Wrap(
                 err, "Couldn't determine table name for object",
             )
         }
+
+        if alias == "" {
+            alias, err = self.nextAlias()
+            if err != nil {
+                return err
+            }
+        } else {
+            self.explicitAliases[alias] = true
+        }
+
+        // An object read from another table still stands in for its own
+        // table if that isn't otherwise included.
+        modelTable := tableName
+        if overrideTable != "" {
+            tableName = overrideTable
+        }
+
         self.aliasTable[alias] = tableName
-        self.tableAlias[tableName] = alias
+        self.aliasType[alias] = &objType
+        for _, table := range []string{tableName, modelTable} {
+            if _, ok := self.tableAlias[table]; !ok {
+                self.tableAlias[table] = alias
+            }
+        }
+        if _, ok := self.objectAlias[object]; !ok {
+            self.objectAlias[object] = alias
+        }
 
         self.tableType[tableName] = &objType
-        self.typeTable[objType] = tableName
-
-        self.aliasCounter++
+        if overrideTable == "" {
+            self.typeTable[objType] = tableName
+        }
     }
 
     return nil
@@ -135,10 +394,17 @@ func (self *AliasedTables) AddObjects(objects ...base.Base) error {
 func NewAliasedTables(objects ...base.Base) (*AliasedTables, error) {
     aliasedBases := AliasedTables{
         aliasTable: make(map[string]string, len(objects)),
+        aliasType: make(map[string]*reflect.Type, len(objects)),
         tableAlias: make(map[string]string, len(objects)),
         tableType: make(map[string]*reflect.Type, len(objects)),
         typeTable: make(map[reflect.Type]string, len(objects)),
+        objectAlias: make(map[base.Base]string, len(objects)),
+        nullableAliases: make(map[string]bool),
+        hiddenAliases: make(map[string]bool),
+        explicitAliases: make(map[string]bool),
+        derivedAliases: make(map[string]bool),
         aliasCounter: 0,
+        subqueryPrefixes: make(map[interface{}]string),
     }
 
     err := aliasedBases.AddObjects(objects...)
@@ -10,6 +10,9 @@ import (
 
 type testObjectAT struct {}
 type testOtherObjectAT struct {}
+type testSizedObjectAT struct {
+    Id int64 `db:"id"`
+}
 
 var _ = Describe("AliasedTables", func() {
     var (
@@ -90,4 +93,86 @@ var _ = Describe("AliasedTables", func() {
         Expect(err).ToNot(HaveOccurred())
         Expect(aliasedTables.Aliases()).To(HaveLen(1))
     })
+    It("should be able to alias the same table more than once", func() {
+        aliasedTables, err = NewAliasedTables()
+        Expect(err).ToNot(HaveOccurred())
+        first := &testSizedObjectAT{}
+        second := Aliased(&testSizedObjectAT{}, "second")
+        err := aliasedTables.AddObjects(first, second)
+        Expect(err).ToNot(HaveOccurred())
+
+        Expect(aliasedTables.Aliases()).To(ConsistOf("a", "second"))
+        Expect(aliasedTables.TableForAlias("second")).To(
+            Equal("test_sized_object_ats"),
+        )
+        Expect(*aliasedTables.TypeForAlias("second")).To(
+            Equal(reflect.TypeOf(*first)),
+        )
+        alias, ok := aliasedTables.AliasForTable("test_sized_object_ats")
+        Expect(ok).To(BeTrue())
+        Expect(alias).To(Equal("a"))
+
+        alias, err = aliasedTables.ObjectAlias(second)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(alias).To(Equal("second"))
+        alias, err = aliasedTables.ObjectAlias(second.Object)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(alias).To(Equal("second"))
+    })
+    It("should reject invalid or duplicate aliases", func() {
+        aliasedTables, err = NewAliasedTables()
+        Expect(err).ToNot(HaveOccurred())
+        err := aliasedTables.AddObjects(Aliased(object, "not_valid"))
+        Expect(err).To(HaveOccurred())
+
+        err = aliasedTables.AddObjects(Aliased(object, "first"))
+        Expect(err).ToNot(HaveOccurred())
+        err = aliasedTables.AddObjects(Aliased(object2, "first"))
+        Expect(err).To(HaveOccurred())
+    })
+    It("should prefix generated aliases when nested", func() {
+        parent, err := NewAliasedTables()
+        Expect(err).ToNot(HaveOccurred())
+        outer := &testSizedObjectAT{}
+        err = parent.AddObjects(outer)
+        Expect(err).ToNot(HaveOccurred())
+
+        aliasedTables, err = NewAliasedTables()
+        Expect(err).ToNot(HaveOccurred())
+        inner := &testSizedObjectAT{}
+        named := Aliased(&testSizedObjectAT{}, "named")
+        err = aliasedTables.AddObjects(inner, named)
+        Expect(err).ToNot(HaveOccurred())
+
+        prefix := parent.SubqueryPrefix(aliasedTables)
+        Expect(prefix).To(Equal("s1"))
+        Expect(parent.SubqueryPrefix(aliasedTables)).To(Equal(prefix))
+
+        nested, rename := aliasedTables.Nested(parent, prefix)
+        Expect(rename("a")).To(Equal("s1a"))
+        Expect(rename("named")).To(Equal("named"))
+        Expect(nested.Aliases()).To(ConsistOf("s1a", "named"))
+
+        alias, err := nested.ObjectAlias(inner)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(alias).To(Equal("s1a"))
+        alias, err = nested.ObjectAlias(outer)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(alias).To(Equal("a"))
+    })
+    It("should be able to read an object from another table", func() {
+        aliasedTables, err = NewAliasedTables()
+        Expect(err).ToNot(HaveOccurred())
+        cte := InTable(&testSizedObjectAT{}, "tree")
+        err := aliasedTables.AddObjects(cte)
+        Expect(err).ToNot(HaveOccurred())
+
+        Expect(aliasedTables.TableForAlias("a")).To(Equal("tree"))
+        alias, err := aliasedTables.ObjectAlias(cte)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(alias).To(Equal("a"))
+        alias, err = aliasedTables.ObjectAlias(&testSizedObjectAT{})
+        Expect(err).ToNot(HaveOccurred())
+        Expect(alias).To(Equal("a"))
+    })
 })
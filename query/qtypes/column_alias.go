@@ -14,11 +14,19 @@ type ColumnAlias struct {
     ColumnName string
 }
 
+// ColumnAliasField is a ColumnAlias paired with the name of the field it's
+// written to. Columns that don't belong to any object have no TableAlias or
+// FieldName.
 type ColumnAliasField struct {
     ColumnAlias
     FieldName string
 }
 
+// Extra indicates that the column doesn't belong to any object.
+func (self ColumnAliasField) Extra() bool {
+    return self.TableAlias == ""
+}
+
 func (self ColumnAlias) String() string {
     return fmt.Sprintf("%s_%s", self.TableAlias, self.ColumnName)
 }
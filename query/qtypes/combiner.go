@@ -23,6 +23,17 @@ const (
     OrCombiner
     NotCombiner
     CommaCombiner
+    IsCombiner
+    IsNotCombiner
+    LikeCombiner
+    // ILikeCombiner is a case insensitive LIKE; it's only supported by
+    // postgres.
+    ILikeCombiner
+    BetweenCombiner
+    NotInCombiner
+    // IsDistinctFromCombiner is a NULL-aware not equal; it's only supported
+    // by postgres.
+    IsDistinctFromCombiner
 )
 
 func (self Combiner) String() string {
@@ -49,6 +60,20 @@ func (self Combiner) String() string {
         return "NOT"
         case CommaCombiner:
         return ","
+        case IsCombiner:
+        return "IS"
+        case IsNotCombiner:
+        return "IS NOT"
+        case LikeCombiner:
+        return "LIKE"
+        case ILikeCombiner:
+        return "ILIKE"
+        case BetweenCombiner:
+        return "BETWEEN"
+        case NotInCombiner:
+        return "NOT IN"
+        case IsDistinctFromCombiner:
+        return "IS DISTINCT FROM"
     }
     panic(errors.Errorf("Unknown combiner %#+v!", self))
 }
@@ -58,7 +83,9 @@ func (self Combiner) Join(parts ...string) string {
     var combinerString string
 
     switch(self) {
-        case AndCombiner, OrCombiner, NotCombiner:
+        case AndCombiner, OrCombiner, NotCombiner, IsCombiner,
+            IsNotCombiner, LikeCombiner, ILikeCombiner, BetweenCombiner,
+            NotInCombiner, IsDistinctFromCombiner:
         combinerString = fmt.Sprintf(" %s ", self.String())
         case CommaCombiner:
         combinerString = fmt.Sprintf("%s ", self.String())
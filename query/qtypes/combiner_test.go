@@ -19,6 +19,26 @@ var allTestCombiners = map[string]Combiner {
     "OR": OrCombiner,
     "NOT": NotCombiner,
     ",": CommaCombiner,
+    "IS": IsCombiner,
+    "IS NOT": IsNotCombiner,
+    "LIKE": LikeCombiner,
+    "ILIKE": ILikeCombiner,
+    "BETWEEN": BetweenCombiner,
+    "NOT IN": NotInCombiner,
+    "IS DISTINCT FROM": IsDistinctFromCombiner,
+}
+
+var spacedTestCombiners = map[Combiner]bool {
+    AndCombiner: true,
+    OrCombiner: true,
+    NotCombiner: true,
+    IsCombiner: true,
+    IsNotCombiner: true,
+    LikeCombiner: true,
+    ILikeCombiner: true,
+    BetweenCombiner: true,
+    NotInCombiner: true,
+    IsDistinctFromCombiner: true,
 }
 
 var _ = Describe("Combiner", func() {
@@ -48,8 +68,7 @@ var _ = Describe("Combiner", func() {
                     arg1, arg2 := "5", "6"
                     combinerString := combiner.Join(arg1, arg2)
                     expectedString := fmt.Sprintf("%s%s%s", arg1, symbol, arg2)
-                    if combiner == OrCombiner || combiner == AndCombiner ||
-                        combiner == NotCombiner {
+                    if spacedTestCombiners[combiner] {
                         expectedString = fmt.Sprintf(
                             "%s %s %s", arg1, symbol, arg2,
                         )
@@ -79,7 +79,7 @@ func NewDefaultCondition(
 ) Queryable {
     var modifiers []ValueModifier
     switch(combiner) {
-        case InCombiner:
+        case InCombiner, NotInCombiner:
         modifiers = append(modifiers, rightParenValueModifier)
     }
 
@@ -174,3 +174,20 @@ func NewMultiListCondition(values ...Queryable) Queryable {
         Combiner: CommaCombiner,
     }
 }
+
+// ExistsCondition creates a sql EXISTS on the provided subquery.
+type ExistsCondition struct {
+    Value Queryable
+}
+
+func (self ExistsCondition) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    valueQueryString, args := self.Value.QueryValue(at)
+    queryString := fmt.Sprintf("(EXISTS %s)", maybeParen(valueQueryString))
+    return queryString, args
+}
+
+func (self ExistsCondition) String() string {
+    return fmt.Sprintf("(EXISTS %s)", maybeParen(self.Value.String()))
+}
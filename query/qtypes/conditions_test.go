@@ -17,7 +17,7 @@ var _ = Describe("Combiner", func() {
             }
             It(fmt.Sprintf("should handle an '%s' condition", symbol), func() {
                 expectedString := fmt.Sprintf("(foo %s 5)", symbol)
-                if combiner == InCombiner {
+                if combiner == InCombiner || combiner == NotInCombiner {
                     expectedString = fmt.Sprintf("(foo %s (5))", symbol)
                 }
 
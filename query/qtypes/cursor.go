@@ -0,0 +1,176 @@
+package qtypes
+
+import (
+    "bytes"
+    "database/sql/driver"
+    "encoding/base64"
+    "encoding/json"
+    "time"
+
+    "github.com/pkg/errors"
+)
+
+// maxCursorLength is the longest cursor which will be decoded; cursors come
+// from clients so anything longer is refused before it's read.
+const maxCursorLength = 4096
+
+// The types of value a cursor can hold. Values are normalized to driver
+// values before encoding so these cover every value a cursor is built from.
+const (
+    cursorStringType = "string"
+    cursorIntType = "int"
+    cursorFloatType = "float"
+    cursorBoolType = "bool"
+    cursorTimeType = "time"
+    cursorBytesType = "bytes"
+)
+
+// cursorValue is a single value in an encoded cursor tagged with its type.
+type cursorValue struct {
+    Type string `json:"t"`
+    Value json.RawMessage `json:"v"`
+}
+
+// encodeCursorValue tags a driver value with its type.
+func encodeCursorValue(value driver.Value) (cursorValue, error) {
+    var typeName string
+    switch typedValue := value.(type) {
+    case string:
+        typeName = cursorStringType
+    case int64:
+        typeName = cursorIntType
+    case float64:
+        typeName = cursorFloatType
+    case bool:
+        typeName = cursorBoolType
+    case time.Time:
+        typeName = cursorTimeType
+        value = typedValue.Format(time.RFC3339Nano)
+    case []byte:
+        typeName = cursorBytesType
+    default:
+        return cursorValue{}, errors.Errorf(
+            "Values of type %T can't be used in a cursor", value,
+        )
+    }
+
+    raw, err := json.Marshal(value)
+    if err != nil {
+        return cursorValue{}, err
+    }
+
+    return cursorValue{Type: typeName, Value: raw}, nil
+}
+
+// decodeCursorValue reads a value back according to its type tag.
+func decodeCursorValue(value cursorValue) (interface{}, error) {
+    if len(value.Value) == 0 || string(value.Value) == "null" {
+        return nil, errors.New("Cursor value is missing")
+    }
+
+    var err error
+    switch value.Type {
+    case cursorStringType:
+        var typedValue string
+        err = json.Unmarshal(value.Value, &typedValue)
+        return typedValue, err
+    case cursorIntType:
+        var typedValue int64
+        err = json.Unmarshal(value.Value, &typedValue)
+        return typedValue, err
+    case cursorFloatType:
+        var typedValue float64
+        err = json.Unmarshal(value.Value, &typedValue)
+        return typedValue, err
+    case cursorBoolType:
+        var typedValue bool
+        err = json.Unmarshal(value.Value, &typedValue)
+        return typedValue, err
+    case cursorTimeType:
+        var rawTime string
+        if err = json.Unmarshal(value.Value, &rawTime); err != nil {
+            return nil, err
+        }
+        return time.Parse(time.RFC3339Nano, rawTime)
+    case cursorBytesType:
+        var typedValue []byte
+        err = json.Unmarshal(value.Value, &typedValue)
+        return typedValue, err
+    }
+
+    return nil, errors.Errorf("Unknown cursor value type '%s'", value.Type)
+}
+
+// EncodeCursor encodes the values of a row's ordering columns into an opaque
+// cursor string.
+func EncodeCursor(values []interface{}) (string, error) {
+    cursorValues := make([]cursorValue, len(values))
+    for i, value := range values {
+        driverValue, err := driver.DefaultParameterConverter.ConvertValue(
+            value,
+        )
+        if err != nil {
+            return "", errors.Wrapf(
+                err, "Error converting cursor value #%d", i,
+            )
+        }
+        if driverValue == nil {
+            return "", errors.Errorf(
+                "Cursor value #%d is NULL, ordering columns used for " +
+                    "cursors can't be NULL",
+                i,
+            )
+        }
+        cursorValues[i], err = encodeCursorValue(driverValue)
+        if err != nil {
+            return "", errors.Wrapf(
+                err, "Error encoding cursor value #%d", i,
+            )
+        }
+    }
+
+    raw, err := json.Marshal(cursorValues)
+    if err != nil {
+        return "", errors.Wrap(err, "Error encoding cursor")
+    }
+
+    return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// DecodeCursor decodes a cursor created by EncodeCursor back into the values
+// of the ordering columns. Cursors are expected to come from clients so only
+// the value types EncodeCursor writes are accepted.
+func DecodeCursor(cursor string) ([]interface{}, error) {
+    if len(cursor) > maxCursorLength {
+        return nil, errors.New("Cursor is too long")
+    }
+    raw, err := base64.RawURLEncoding.DecodeString(cursor)
+    if err != nil {
+        return nil, errors.Wrap(err, "Cursor is malformed")
+    }
+
+    var cursorValues []cursorValue
+    decoder := json.NewDecoder(bytes.NewReader(raw))
+    decoder.DisallowUnknownFields()
+    if err = decoder.Decode(&cursorValues); err != nil {
+        return nil, errors.Wrap(err, "Cursor is malformed")
+    }
+    if decoder.More() {
+        return nil, errors.New("Cursor is malformed")
+    }
+    if len(cursorValues) == 0 {
+        return nil, errors.New("Cursor has no values")
+    }
+
+    values := make([]interface{}, len(cursorValues))
+    for i, cursorValue := range cursorValues {
+        values[i], err = decodeCursorValue(cursorValue)
+        if err != nil {
+            return nil, errors.Wrapf(
+                err, "Cursor value #%d is malformed", i,
+            )
+        }
+    }
+
+    return values, nil
+}
@@ -0,0 +1,55 @@
+package qtypes
+
+import (
+    "encoding/base64"
+    "strings"
+    "time"
+
+    . "github.com/onsi/ginkgo"
+    . "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cursor", func() {
+    encode := func(raw string) string {
+        return base64.RawURLEncoding.EncodeToString([]byte(raw))
+    }
+
+    It("should round trip each type of value", func() {
+        created := time.Date(2020, 3, 4, 5, 6, 7, 8, time.UTC)
+        cursor, err := EncodeCursor([]interface{}{
+            "bob", 7, 1.5, true, created, []byte("raw"),
+        })
+        Expect(err).ToNot(HaveOccurred())
+
+        values, err := DecodeCursor(cursor)
+        Expect(err).ToNot(HaveOccurred())
+        Expect(values).To(Equal([]interface{}{
+            "bob", int64(7), 1.5, true, created, []byte("raw"),
+        }))
+    })
+    It("should refuse NULL values", func() {
+        _, err := EncodeCursor([]interface{}{"bob", nil})
+        Expect(err).To(MatchError(ContainSubstring("Cursor value #1 is NULL")))
+    })
+    It("should refuse garbage cursors", func() {
+        cursors := []string{
+            "not a cursor!",
+            encode("garbage"),
+            encode(`[]`),
+            encode(`[{"t":"int","v":1}] trailing`),
+            encode(`[{"t":"int","v":1.5}]`),
+            encode(`[{"t":"int","v":"1"}]`),
+            encode(`[{"t":"string","v":null}]`),
+            encode(`[{"t":"string"}]`),
+            encode(`[{"t":"map","v":{"a":1}}]`),
+            encode(`[{"t":"time","v":"yesterday"}]`),
+            encode(`[{"t":"int","v":1,"x":2}]`),
+            strings.Repeat("a", maxCursorLength+1),
+        }
+        for _, cursor := range cursors {
+            values, err := DecodeCursor(cursor)
+            Expect(err).To(HaveOccurred(), "cursor %q", cursor)
+            Expect(values).To(BeNil())
+        }
+    })
+})
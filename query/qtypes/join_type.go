@@ -0,0 +1,40 @@
+package qtypes
+
+import (
+    "github.com/pkg/errors"
+)
+
+// JoinType defines how a table is joined into a query.
+type JoinType int
+
+const (
+    UnsetJoinType JoinType = iota
+    InnerJoinType
+    LeftJoinType
+    RightJoinType
+    FullJoinType
+)
+
+func (self JoinType) String() string {
+    switch(self) {
+        case UnsetJoinType, InnerJoinType:
+        return "JOIN"
+        case LeftJoinType:
+        return "LEFT JOIN"
+        case RightJoinType:
+        return "RIGHT JOIN"
+        case FullJoinType:
+        return "FULL OUTER JOIN"
+    }
+    panic(errors.Errorf("Unknown join type %#+v!", self))
+}
+
+// Outer indicates if this join type may produce rows where one side is
+// missing (all NULL).
+func (self JoinType) Outer() bool {
+    switch(self) {
+        case LeftJoinType, RightJoinType, FullJoinType:
+        return true
+    }
+    return false
+}
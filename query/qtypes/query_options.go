@@ -2,6 +2,11 @@ package qtypes
 
 import (
     "fmt"
+    "strings"
+
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/base"
 )
 
 // OptionType defines query option types.
@@ -9,9 +14,12 @@ type OptionType int
 
 const (
     UnsetOptionType OptionType = iota
+    GroupByOptionType
+    HavingOptionType
     OrderByOptionType
     LimitOptionType
     OffsetOptionType
+    LockOptionType
 )
 
 // QueryOption defines a special type of queryable to be used for option on a
@@ -21,6 +29,88 @@ type QueryOption interface {
     OptionType() OptionType
 }
 
+// GroupByOption defines an option that groups the query's rows.
+type GroupByOption struct {
+    Group Queryable
+}
+
+func (self GroupByOption) fmtString(str string) string {
+    return fmt.Sprintf("GROUP BY %s", str)
+}
+
+func (GroupByOption) OptionType() OptionType {
+    return GroupByOptionType
+}
+
+func (self GroupByOption) String() string {
+    return self.fmtString(self.Group.String())
+}
+
+func (self GroupByOption) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    groupStr, groupVals := self.Group.QueryValue(at)
+    return self.fmtString(groupStr), groupVals
+}
+
+// AddStatements appends to a GroupByOption.
+func(self *GroupByOption) AddStatements(statements ...Queryable) {
+    if multiCondition, ok := self.Group.(MultiCondition); ok {
+        if multiCondition.Combiner == CommaCombiner {
+            multiCondition.Values = append(
+                multiCondition.Values, statements...,
+            )
+
+            self.Group = multiCondition
+            return
+        }
+    }
+
+    allStatements := append([]Queryable{self.Group}, statements...)
+    self.Group = NewMultiListCondition(allStatements...)
+}
+
+// HavingOption filters the groups of a grouped query.
+type HavingOption struct {
+    Condition Queryable
+}
+
+func (self HavingOption) fmtString(str string) string {
+    return fmt.Sprintf("HAVING %s", str)
+}
+
+func (HavingOption) OptionType() OptionType {
+    return HavingOptionType
+}
+
+func (self HavingOption) String() string {
+    return self.fmtString(self.Condition.String())
+}
+
+func (self HavingOption) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    conditionStr, conditionVals := self.Condition.QueryValue(at)
+    return self.fmtString(conditionStr), conditionVals
+}
+
+// AddConditions appends to a HavingOption; all conditions must be met.
+func(self *HavingOption) AddConditions(conditions ...Queryable) {
+    if multiCondition, ok := self.Condition.(MultiCondition); ok {
+        if multiCondition.Combiner == AndCombiner {
+            multiCondition.Values = append(
+                multiCondition.Values, conditions...,
+            )
+
+            self.Condition = multiCondition
+            return
+        }
+    }
+
+    allConditions := append([]Queryable{self.Condition}, conditions...)
+    self.Condition = NewMultiAndCondition(allConditions...)
+}
+
 // OrderByOption defines an option that orders the query by
 type OrderByOption struct {
     Order Queryable
@@ -105,3 +195,93 @@ func (self OffsetOption) QueryValue(
 ) (string, []interface{}) {
     return self.fmtString(), nil
 }
+
+// LockStrength is the kind of row-level lock a query takes.
+type LockStrength int
+
+const (
+    ForUpdateLockStrength LockStrength = iota
+    ForShareLockStrength
+)
+
+func (self LockStrength) String() string {
+    if self == ForShareLockStrength {
+        return "FOR SHARE"
+    }
+
+    return "FOR UPDATE"
+}
+
+// LockWait is how a locking query behaves when rows are already locked.
+type LockWait int
+
+const (
+    // WaitLockWait waits for the rows to be unlocked.
+    WaitLockWait LockWait = iota
+    // NoWaitLockWait fails immediately.
+    NoWaitLockWait
+    // SkipLockedLockWait leaves the locked rows out of the results.
+    SkipLockedLockWait
+)
+
+func (self LockWait) String() string {
+    switch self {
+    case NoWaitLockWait:
+        return "NOWAIT"
+    case SkipLockedLockWait:
+        return "SKIP LOCKED"
+    }
+
+    return ""
+}
+
+// LockOption locks the rows read by the query for the rest of the
+// transaction. Of limits the lock to the rows of the provided objects'
+// tables.
+type LockOption struct {
+    Strength LockStrength
+    Of []base.Base
+    Wait LockWait
+}
+
+func (LockOption) OptionType() OptionType {
+    return LockOptionType
+}
+
+func (self LockOption) fmtString(tables []string) string {
+    parts := []string{self.Strength.String()}
+    if len(tables) != 0 {
+        parts = append(parts, "OF " + strings.Join(tables, ", "))
+    }
+    if wait := self.Wait.String(); wait != "" {
+        parts = append(parts, wait)
+    }
+
+    return strings.Join(parts, " ")
+}
+
+func (self LockOption) String() string {
+    tables := make([]string, len(self.Of))
+    for i, object := range self.Of {
+        tables[i] = fmt.Sprintf("%T", UnaliasedObject(object))
+    }
+
+    return self.fmtString(tables)
+}
+
+func (self LockOption) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    tables := make([]string, len(self.Of))
+    for i, object := range self.Of {
+        alias, err := at.ObjectAlias(object)
+        if err != nil {
+            at.ReportError(errors.Wrap(
+                err, "Error while finding table to lock",
+            ))
+        }
+        tables[i] = alias
+    }
+
+    return self.fmtString(tables), nil
+}
@@ -64,6 +64,41 @@ var _ = Describe("QueryOption", func() {
 
                 queryString, args := q.QueryValue(aliasedTables)
 
+                Expect(queryString).To(Equal(expectedQueryString))
+                Expect(args).To(BeEmpty())
+            })
+        })
+        Describe("GroupByOption", func() {
+            It("should create a proper group by clause", func() {
+                expectedQueryString := "GROUP BY a.foo, a.bar"
+                q := GroupByOption{
+                    Group: TableColumnQueryable{
+                        TableName: "test_object_query_options",
+                        ColumnName: "foo",
+                    },
+                }
+                q.AddStatements(TableColumnQueryable{
+                    TableName: "test_object_query_options",
+                    ColumnName: "bar",
+                })
+
+                queryString, args := q.QueryValue(aliasedTables)
+
+                Expect(queryString).To(Equal(expectedQueryString))
+                Expect(args).To(BeEmpty())
+            })
+        })
+        Describe("HavingOption", func() {
+            It("should create a proper having clause", func() {
+                expectedQueryString := "HAVING COUNT(a.foo) > 1 AND " +
+                    "MAX(a.foo) < 5"
+                q := HavingOption{
+                    Condition: LiteralQueryable{Value: "COUNT(a.foo) > 1"},
+                }
+                q.AddConditions(LiteralQueryable{Value: "MAX(a.foo) < 5"})
+
+                queryString, args := q.QueryValue(aliasedTables)
+
                 Expect(queryString).To(Equal(expectedQueryString))
                 Expect(args).To(BeEmpty())
             })
@@ -106,6 +141,19 @@ var _ = Describe("QueryOption", func() {
 
                 queryString := q.String()
 
+                Expect(queryString).To(Equal(expectedQueryString))
+            })
+        })
+        Describe("LockOption", func() {
+            It("should create a proper locking clause", func() {
+                expectedQueryString := "FOR SHARE NOWAIT"
+                q := LockOption{
+                    Strength: ForShareLockStrength,
+                    Wait: NoWaitLockWait,
+                }
+
+                queryString := q.String()
+
                 Expect(queryString).To(Equal(expectedQueryString))
             })
         })
@@ -23,15 +23,19 @@ type QueryResult struct {
     aliasedTables *AliasedTables
     aliasObjValPtr AliasObjValMap
     columnAliasFields []ColumnAliasField
+    nullAliases map[string]bool
+    extraValues map[string]interface{}
 
     closeAfterWrite bool
 }
 
 // WriteTo writes the result row into the provided objects automatically
-// determining which objects to write what data.
+// determining which objects to write what data. Objects wrapped with Aliased
+// are written with the data for that alias.
 func (self QueryResult) WriteTo(objects ...base.Base) error {
     aliasObjMap := make(AliasObjValMap, len(objects))
-    for _, object := range objects {
+    for _, aliasedObject := range objects {
+        object := UnaliasedObject(aliasedObject)
         objValPtr := reflect.ValueOf(object)
         if objValPtr.Kind() != reflect.Ptr {
             return errors.Errorf(
@@ -51,7 +55,7 @@ func (self QueryResult) WriteTo(objects ...base.Base) error {
             )
         }
 
-        objAlias, err := self.aliasedTables.ObjectAlias(object.(base.Base))
+        objAlias, err := self.aliasedTables.ObjectAlias(aliasedObject)
         if err != nil {
             return err
         }
@@ -59,7 +63,70 @@ func (self QueryResult) WriteTo(objects ...base.Base) error {
         aliasObjMap[objAlias] = &objValPtr
     }
 
-    return readRowIntoObjs(self.rows, aliasObjMap, self.columnAliasFields)
+    return readRowIntoObjs(
+        self.rows,
+        self.aliasedTables,
+        aliasObjMap,
+        self.columnAliasFields,
+        self.nullAliases,
+        self.extraValues,
+    )
+}
+
+// ExtraValues retrieves the values of the columns in the last row written
+// that don't belong to any object such as aggregates.
+func (self QueryResult) ExtraValues() map[string]interface{} {
+    return self.extraValues
+}
+
+// columnValues retrieves the values of the provided columns in the last row
+// written.
+func (self QueryResult) columnValues(
+    columns []string,
+) ([]interface{}, error) {
+    values := make([]interface{}, len(columns))
+    for i, column := range columns {
+        found := false
+        for _, columnAliasField := range self.columnAliasFields {
+            if columnAliasField.Extra() {
+                if columnAliasField.ColumnName != column {
+                    continue
+                }
+                values[i] = self.extraValues[column]
+                found = true
+                break
+            }
+            if columnAliasField.String() != column {
+                continue
+            }
+            alias := columnAliasField.TableAlias
+            objValPtr, ok := self.aliasObjValPtr[alias]
+            if !ok || self.nullAliases[alias] {
+                return nil, errors.Errorf(
+                    "Column '%s' wasn't written in the last row", column,
+                )
+            }
+            field := objValPtr.Elem().FieldByName(
+                columnAliasField.FieldName,
+            )
+            values[i] = field.Interface()
+            found = true
+            break
+        }
+        if !found {
+            return nil, errors.Errorf(
+                "Column '%s' is not in the results", column,
+            )
+        }
+    }
+
+    return values, nil
+}
+
+// AliasMissing indicates that the object for the provided alias was entirely
+// NULL in the last row written; this happens with outer joins.
+func (self QueryResult) AliasMissing(alias string) bool {
+    return self.nullAliases[alias]
 }
 
 // Close closes this QueryResult's rows.
@@ -72,13 +139,40 @@ func (self QueryResult) Close() error {
     return nil
 }
 
+// nullableScanTarget captures a value that might be NULL so it can be set on
+// a field after the whole row has been read.
+type nullableScanTarget struct {
+    value interface{}
+}
+
+func (self *nullableScanTarget) Scan(src interface{}) error {
+    if raw, ok := src.([]byte); ok {
+        // Drivers may reuse this memory once the row has been read.
+        src = append([]byte(nil), raw...)
+    }
+    self.value = src
+    return nil
+}
+
 func readRowIntoObjs(
     rows *sqlx.Rows,
+    aliasedTables *AliasedTables,
     aliasObjVals AliasObjValMap,
     columnAliasFields []ColumnAliasField,
+    nullAliases map[string]bool,
+    extraValues map[string]interface{},
 ) error {
     values := make([]interface{}, len(columnAliasFields))
+    fields := make([]reflect.Value, len(columnAliasFields))
+    nullableTargets := make(map[int]*nullableScanTarget)
+    extraTargets := make(map[string]*nullableScanTarget)
     for i, columnAliasField := range columnAliasFields {
+        if columnAliasField.Extra() {
+            target := &nullableScanTarget{}
+            extraTargets[columnAliasField.ColumnName] = target
+            values[i] = target
+            continue
+        }
         objVal, ok := aliasObjVals[columnAliasField.TableAlias]
         if !ok {
             return errors.Errorf(
@@ -95,6 +189,15 @@ func readRowIntoObjs(
                 columnAliasField.FieldName,
             )
         }
+        fields[i] = field
+
+        if aliasedTables.AliasNullable(columnAliasField.TableAlias) {
+            target := &nullableScanTarget{}
+            nullableTargets[i] = target
+            values[i] = target
+            continue
+        }
+
         // Stole from reflectx: https://tinyurl.com/yc3lpeam
         if field.Kind() == reflect.Ptr && field.IsNil() {
             alloc := reflect.New(refl.Deref(field.Type()))
@@ -107,7 +210,55 @@ func readRowIntoObjs(
         values[i] = field.Addr().Interface()
     }
 
-    return rows.Scan(values...)
+    err := rows.Scan(values...)
+    if err != nil {
+        return err
+    }
+    for column, target := range extraTargets {
+        extraValues[column] = target.value
+    }
+    if len(nullableTargets) == 0 {
+        return nil
+    }
+
+    aliasHasValue := make(map[string]bool)
+    for i, target := range nullableTargets {
+        alias := columnAliasFields[i].TableAlias
+        if _, ok := aliasHasValue[alias]; !ok {
+            aliasHasValue[alias] = false
+        }
+        if target.value != nil {
+            aliasHasValue[alias] = true
+        }
+    }
+    for alias, hasValue := range aliasHasValue {
+        if !hasValue {
+            nullAliases[alias] = true
+        } else {
+            delete(nullAliases, alias)
+        }
+    }
+
+    for i, target := range nullableTargets {
+        if nullAliases[columnAliasFields[i].TableAlias] {
+            continue
+        }
+        field := fields[i]
+        if target.value == nil {
+            field.Set(reflect.Zero(field.Type()))
+            continue
+        }
+        err := refl.InitSetField(field, reflect.ValueOf(target.value))
+        if err != nil {
+            return errors.Wrapf(
+                err,
+                "Error setting column '%s'",
+                columnAliasFields[i].ColumnName,
+            )
+        }
+    }
+
+    return nil
 }
 
 // NewQueryResult creates a new QueryResult.
@@ -122,6 +273,8 @@ func NewQueryResult(
         aliasedTables: aliasedTables,
         aliasObjValPtr: make(AliasObjValMap),
         columnAliasFields: columnAliasFields,
+        nullAliases: make(map[string]bool),
+        extraValues: make(map[string]interface{}),
         closeAfterWrite: true,
     }, nil
 }
@@ -2,6 +2,7 @@ package qtypes
 
 import (
     "reflect"
+    "strings"
 
     "github.com/jmoiron/sqlx"
     "github.com/pkg/errors"
@@ -13,7 +14,8 @@ import (
 // BaseSlicePointer is a pointer to a slice of pointers to bases like:
 //   `*[]*MyObject`
 // It is represented by an interface so that it can take in a slice of any
-// custom object you've implemented in your project.
+// custom object you've implemented in your project. It may also be wrapped
+// with Aliased to write the results for a specific alias.
 type BaseSlicePointer interface{}
 
 // QueryResults represents a set of results which generate QueryResult per row
@@ -28,6 +30,10 @@ type QueryResults struct {
     columns []string
     columnAliasFields []ColumnAliasField
     aliasesInSelect map[string]bool
+    extraColumns map[string]bool
+    cursorColumns []string
+    cursorValues []interface{}
+    structScanners map[reflect.Type]*StructScanner
 
     closed bool
 }
@@ -36,22 +42,42 @@ func columnsToFieldNames(
     columnNames []string,
     typeBSFieldMap map[reflect.Type]*refl.GroupedFieldsWithBS,
     aliasedTables *AliasedTables,
+    extraColumns map[string]bool,
 ) ([]ColumnAliasField, map[string]bool, error) {
     columnAliasFields := make([]ColumnAliasField, len(columnNames))
     aliasesInSelect := make(map[string]bool, len(columnNames))
     for i, column := range columnNames {
+        // Columns that don't belong to an object in the query (such as
+        // aggregates) are kept as extra values instead of written to fields.
+        extraColumn := ColumnAliasField{
+            ColumnAlias: ColumnAlias{
+                ColumnName: column,
+            },
+        }
+
+        if extraColumns[strings.ToLower(column)] {
+            columnAliasFields[i] = extraColumn
+            continue
+        }
         columnAlias, ok := ColumnAliasFromString(column)
         if !ok {
-            return nil, nil, errors.Errorf(
-                "Unexpected column in result: '%s'",
-                column,
-            )
+            columnAliasFields[i] = extraColumn
+            continue
         }
 
-        aliasesInSelect[columnAlias.TableAlias] = true
-
         objType := aliasedTables.TypeForAlias(columnAlias.TableAlias)
-        tagValBSFields := *typeBSFieldMap[*objType]
+        if objType == nil {
+            columnAliasFields[i] = extraColumn
+            continue
+        }
+        tagValBSFieldsPtr, ok := typeBSFieldMap[*objType]
+        if !ok || tagValBSFieldsPtr == nil {
+            columnAliasFields[i] = extraColumn
+            continue
+        }
+        tagValBSFields := *tagValBSFieldsPtr
+
+        aliasesInSelect[columnAlias.TableAlias] = true
 
         var fieldName string
         if bsField, ok := tagValBSFields[columnAlias.ColumnName]; ok {
@@ -98,6 +124,7 @@ func (self *QueryResults) Next() bool {
             self.columns,
             self.typeBSFieldMap,
             self.aliasedTables,
+            self.extraColumns,
         )
         if err != nil {
             self.lastError = err
@@ -133,20 +160,23 @@ func (self *QueryResults) Err() error {
 }
 
 // Close closes this QueryResults' rows and commits/rollsback the transaction
-// it wraps. This can be safely called multiple times.
+// it wraps. A transaction which belongs to the caller is left open. This can
+// be safely called multiple times.
 func (self *QueryResults) Close() error {
     if !self.closed {
         self.rows.Close()
-        err := self.tx.Commit()
-        if err != nil {
-            rollErr := self.tx.Rollback()
-            if rollErr != nil {
-                return errors.Wrapf(
-                    err,
-                    "Error rolling back transaction caused by error while " +
-                        "commiting '%s'",
-                    rollErr.Error(),
-                )
+        if self.tx != nil {
+            err := self.tx.Commit()
+            if err != nil {
+                rollErr := self.tx.Rollback()
+                if rollErr != nil {
+                    return errors.Wrapf(
+                        err,
+                        "Error rolling back transaction caused by error " +
+                            "while commiting '%s'",
+                        rollErr.Error(),
+                    )
+                }
             }
         }
 
@@ -178,6 +208,9 @@ func (self *QueryResults) write(
                 )
             }
             self.rows.Close()
+            if self.tx == nil {
+                return
+            }
             newErr := self.tx.Rollback()
             if newErr != nil {
                 retErr = errors.Wrapf(
@@ -194,8 +227,21 @@ func (self *QueryResults) write(
         }
     }()
 
+    if len(objectSlices) == 1 {
+        if mapSlice, ok := objectSlices[0].(*[]map[string]interface{}); ok {
+            return self.writeMaps(count, mapSlice)
+        }
+    }
+
     elemTypes := make([]reflect.Type, len(objectSlices))
+    sliceAliases := make([]string, len(objectSlices))
+    targetSlices := make([]BaseSlicePointer, len(objectSlices))
     for i, objectSlice := range objectSlices {
+        if aliasedSlice, ok := objectSlice.(*AliasedObject); ok {
+            sliceAliases[i] = aliasedSlice.Alias
+            objectSlice = aliasedSlice.Object
+        }
+        targetSlices[i] = objectSlice
         typ := reflect.TypeOf(objectSlice)
         if typ.Kind() != reflect.Ptr {
             return errors.Errorf(
@@ -242,15 +288,25 @@ func (self *QueryResults) write(
 
     for i := 0; self.Next() && checkCount(count); i++ {
         elemValues := make([]base.Base, len(elemTypes))
+        elemAliases := make([]string, len(elemTypes))
         for i, elemTypePtr := range elemTypes {
             elemType := elemTypePtr.Elem()
             elemVal := reflect.New(elemType).Interface()
-            elemAlias, err := self.aliasedTables.ObjectAlias(
-                elemVal.(base.Base),
-            )
+            var elemObject base.Base = elemVal
+            if sliceAliases[i] != "" {
+                elemObject = Aliased(elemVal, sliceAliases[i])
+            }
+            elemAlias, err := self.aliasedTables.ObjectAlias(elemObject)
             if err != nil {
                 return errors.WithStack(err)
             }
+            if *self.aliasedTables.TypeForAlias(elemAlias) != elemType {
+                return errors.Errorf(
+                    "Can't write slice of type '%s' for alias '%s'.",
+                    elemType,
+                    elemAlias,
+                )
+            }
             if _, ok := self.aliasesInSelect[elemAlias]; !ok {
                 return errors.Errorf(
                     "Can't write slice of type '%s', object is not in select.",
@@ -258,20 +314,34 @@ func (self *QueryResults) write(
                 )
             }
 
-            elemValues[i] = elemVal
+            elemValues[i] = elemObject
+            elemAliases[i] = elemAlias
         }
         nextResult := self.GetResult()
         err := nextResult.WriteTo(elemValues...)
         if err != nil {
             return errors.WithStack(err)
         }
+        if len(self.cursorColumns) != 0 {
+            cursorValues, err := nextResult.columnValues(self.cursorColumns)
+            if err != nil {
+                return errors.Wrap(err, "Error reading cursor values")
+            }
+            self.cursorValues = cursorValues
+        }
 
         for i, elemVal := range elemValues {
-            objSlice := objectSlices[i]
+            objSlice := targetSlices[i]
             sliceVal := reflect.ValueOf(objSlice)
+            newElem := reflect.ValueOf(UnaliasedObject(elemVal))
+            if nextResult.AliasMissing(elemAliases[i]) {
+                // The object wasn't present in this row (outer join) so
+                // leave it empty rather than writing a zeroed object.
+                newElem = reflect.Zero(newElem.Type())
+            }
             newStruct := reflect.Append(
                 sliceVal.Elem(),
-                reflect.ValueOf(elemVal),
+                newElem,
             )
 
             sliceVal.Elem().Set(newStruct)
@@ -281,14 +351,129 @@ func (self *QueryResults) write(
     return nil
 }
 
+// writeMaps writes up to `count` rows to the provided slice as maps of
+// column name to value.
+func (self *QueryResults) writeMaps(
+    count int, mapSlice *[]map[string]interface{},
+) error {
+    for written := 0; count < 0 || written < count; written++ {
+        if !self.Next() {
+            break
+        }
+        row, err := self.ScanMap()
+        if err != nil {
+            return err
+        }
+        *mapSlice = append(*mapSlice, row)
+    }
+
+    return self.Err()
+}
+
+// ScanStruct reads the current row (prepared with Next) into dest which
+// should be a pointer to any struct, it doesn't have to be a model. Columns
+// are matched to fields by their `db` tag or field name and every column
+// must have a field.
+func (self *QueryResults) ScanStruct(dest interface{}) error {
+    if self.nextResult == nil {
+        return errors.New("Next must be called before scanning a row")
+    }
+    destVal := reflect.ValueOf(dest)
+    if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+        return errors.Errorf(
+            "ScanStruct requires a pointer to a struct not %T", dest,
+        )
+    }
+    destVal = destVal.Elem()
+
+    scanner, ok := self.structScanners[destVal.Type()]
+    if !ok {
+        var err error
+        scanner, err = NewStructScanner(destVal.Type(), self.columns)
+        if err != nil {
+            return err
+        }
+        if self.structScanners == nil {
+            self.structScanners = make(map[reflect.Type]*StructScanner)
+        }
+        self.structScanners[destVal.Type()] = scanner
+    }
+
+    return scanner.Scan(self.rows, destVal)
+}
+
+// ScanMap reads the current row (prepared with Next) into a map of column
+// name to value. Values are as they were returned by the driver.
+func (self *QueryResults) ScanMap() (map[string]interface{}, error) {
+    if self.nextResult == nil {
+        return nil, errors.New("Next must be called before scanning a row")
+    }
+
+    targets := make([]*nullableScanTarget, len(self.columns))
+    values := make([]interface{}, len(self.columns))
+    for i := range self.columns {
+        targets[i] = &nullableScanTarget{}
+        values[i] = targets[i]
+    }
+
+    err := self.rows.Scan(values...)
+    if err != nil {
+        return nil, err
+    }
+
+    row := make(map[string]interface{}, len(self.columns))
+    for i, column := range self.columns {
+        row[column] = targets[i].value
+    }
+
+    return row, nil
+}
+
+// SetExtraColumns marks the provided columns (such as aliased expressions) as
+// extra values so they're never written to an object's fields even if their
+// name looks like one of an object's columns.
+func (self *QueryResults) SetExtraColumns(columns ...string) {
+    self.extraColumns = make(map[string]bool, len(columns))
+    for _, column := range columns {
+        self.extraColumns[strings.ToLower(column)] = true
+    }
+}
+
+// SetCursorColumns sets the columns that identify a row's position in the
+// ordering of the results. Their values in the last row written make up the
+// Cursor.
+func (self *QueryResults) SetCursorColumns(columns ...string) {
+    self.cursorColumns = columns
+}
+
+// Cursor returns an opaque cursor for the last row written by WriteN or
+// WriteAllTo which can be passed to Query.After or Query.Before to continue
+// from that row.
+func (self *QueryResults) Cursor() (string, error) {
+    if len(self.cursorColumns) == 0 {
+        return "", errors.New(
+            "Results have no cursor, the query must be ordered by " +
+                "selected columns",
+        )
+    }
+    if self.cursorValues == nil {
+        return "", errors.New("No rows have been written")
+    }
+
+    return EncodeCursor(self.cursorValues)
+}
+
 // WriteAllTo writes all results to the provided slices, automatically
-// determining which match which. This operation closes the transaction.
+// determining which match which. A single `*[]map[string]interface{}` can be
+// provided instead to write every row as a map of column name to value. This
+// operation closes the transaction.
 func (self *QueryResults) WriteAllTo(objectSlices ...BaseSlicePointer) error {
     return self.write(-1, true, objectSlices)
 }
 
 // NewQueryResults returns a new QueryResults from a finished query with
-// pending rows.
+// pending rows. tx may be nil if the rows were read in a transaction the
+// caller finishes themselves.
 func NewQueryResults(
     tx *sqlx.Tx,
     rows *sqlx.Rows,
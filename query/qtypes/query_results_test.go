@@ -19,6 +19,11 @@ type testObjectQR struct {
     Name string
 }
 
+type secondTestObjectQR struct {
+    Id int64 `db:"id"`
+    Name string `db:"name"`
+}
+
 var _ = Describe("QueryResults", func() {
     var (
         db *sql.DB
@@ -106,4 +111,189 @@ var _ = Describe("QueryResults", func() {
         Expect(results[0].Id).To(Equal(expectedId))
         Expect(results[0].Name).To(Equal(expectedName))
     })
+    It("should leave missing outer joined objects empty", func() {
+        expectedRows := sqlmock.NewRows(
+            []string{"a_id", "a_name", "b_id", "b_name"},
+        ).AddRow(1, "foo", nil, nil).AddRow(2, "bar", 3, "baz")
+        mock.ExpectBegin()
+        mock.ExpectQuery("SELECT").WillReturnRows(expectedRows)
+        mock.ExpectCommit()
+        tx, err := dbx.Beginx()
+        Expect(err).ToNot(HaveOccurred())
+
+        rows, err := tx.Queryx("SELECT")
+        Expect(err).ToNot(HaveOccurred())
+
+        object := &testObjectQR{}
+        object2 := &secondTestObjectQR{}
+
+        at, err := NewAliasedTables(object, object2)
+        Expect(err).ToNot(HaveOccurred())
+        at.SetAliasNullable("b", true)
+
+        typeBSFieldMap := make(map[reflect.Type]*refl.GroupedFieldsWithBS)
+        for _, obj := range []interface{}{object, object2} {
+            fieldGroupings := refl.GetGroupedFieldsWithBS(
+                obj,
+                refl.GroupFieldsByTagValue("db", "dbfkey"),
+            )
+            typeBSFieldMap[refl.Deref(reflect.TypeOf(obj))] = fieldGroupings[0]
+        }
+
+        qr := NewQueryResults(tx, rows, at, typeBSFieldMap)
+        Expect(qr).ToNot(BeNil())
+
+        results := make([]*testObjectQR, 0)
+        results2 := make([]*secondTestObjectQR, 0)
+        err = qr.WriteAllTo(&results, &results2)
+        Expect(err).ToNot(HaveOccurred())
+
+        Expect(results).To(HaveLen(2))
+        Expect(results2).To(HaveLen(2))
+        Expect(results[0].Id).To(Equal(int64(1)))
+        Expect(results2[0]).To(BeNil())
+        Expect(results2[1].Id).To(Equal(int64(3)))
+        Expect(results2[1].Name).To(Equal("baz"))
+    })
+    It("should write results for aliases of the same type", func() {
+        expectedRows := sqlmock.NewRows(
+            []string{"a_id", "a_name", "parent_id", "parent_name"},
+        ).AddRow(1, "child", 2, "parent")
+        mock.ExpectBegin()
+        mock.ExpectQuery("SELECT").WillReturnRows(expectedRows)
+        mock.ExpectCommit()
+        tx, err := dbx.Beginx()
+        Expect(err).ToNot(HaveOccurred())
+
+        rows, err := tx.Queryx("SELECT")
+        Expect(err).ToNot(HaveOccurred())
+
+        child := &secondTestObjectQR{}
+        parent := Aliased(&secondTestObjectQR{}, "parent")
+
+        at, err := NewAliasedTables(child, parent)
+        Expect(err).ToNot(HaveOccurred())
+
+        typeBSFieldMap := make(map[reflect.Type]*refl.GroupedFieldsWithBS)
+        fieldGroupings := refl.GetGroupedFieldsWithBS(
+            child,
+            refl.GroupFieldsByTagValue("db", "dbfkey"),
+        )
+        typeBSFieldMap[refl.Deref(reflect.TypeOf(child))] = fieldGroupings[0]
+
+        qr := NewQueryResults(tx, rows, at, typeBSFieldMap)
+        Expect(qr).ToNot(BeNil())
+
+        children := make([]*secondTestObjectQR, 0)
+        parents := make([]*secondTestObjectQR, 0)
+        err = qr.WriteAllTo(&children, Aliased(&parents, "parent"))
+        Expect(err).ToNot(HaveOccurred())
+
+        Expect(children).To(HaveLen(1))
+        Expect(parents).To(HaveLen(1))
+        Expect(children[0].Name).To(Equal("child"))
+        Expect(parents[0].Id).To(Equal(int64(2)))
+        Expect(parents[0].Name).To(Equal("parent"))
+    })
+    It("should keep columns that don't belong to objects", func() {
+        expectedRows := sqlmock.NewRows(
+            []string{"a_id", "a_name", "total", "post_count"},
+        ).AddRow(1, "foo", 3, 4)
+        mock.ExpectBegin()
+        mock.ExpectQuery("SELECT").WillReturnRows(expectedRows)
+        mock.ExpectCommit()
+        tx, err := dbx.Beginx()
+        Expect(err).ToNot(HaveOccurred())
+
+        rows, err := tx.Queryx("SELECT")
+        Expect(err).ToNot(HaveOccurred())
+
+        object := &secondTestObjectQR{}
+        at, err := NewAliasedTables(object)
+        Expect(err).ToNot(HaveOccurred())
+
+        typeBSFieldMap := make(map[reflect.Type]*refl.GroupedFieldsWithBS)
+        fieldGroupings := refl.GetGroupedFieldsWithBS(
+            object,
+            refl.GroupFieldsByTagValue("db", "dbfkey"),
+        )
+        typeBSFieldMap[refl.Deref(reflect.TypeOf(object))] = fieldGroupings[0]
+
+        qr := NewQueryResults(tx, rows, at, typeBSFieldMap)
+        Expect(qr.Next()).To(BeTrue())
+        result := qr.GetResult()
+        Expect(result.WriteTo(object)).To(Succeed())
+        Expect(object.Name).To(Equal("foo"))
+        Expect(result.ExtraValues()).To(HaveLen(2))
+        Expect(result.ExtraValues()).To(
+            HaveKeyWithValue("total", BeEquivalentTo(3)),
+        )
+        Expect(result.ExtraValues()).To(
+            HaveKeyWithValue("post_count", BeEquivalentTo(4)),
+        )
+        Expect(qr.Close()).To(Succeed())
+    })
+    It("should be able to scan rows into any struct", func() {
+        type nameTotal struct {
+            Name string `db:"name"`
+            Total int64
+        }
+        expectedRows := sqlmock.NewRows(
+            []string{"name", "total"},
+        ).AddRow("foo", 3).AddRow("bar", nil)
+        mock.ExpectBegin()
+        mock.ExpectQuery("SELECT").WillReturnRows(expectedRows)
+        mock.ExpectCommit()
+        tx, err := dbx.Beginx()
+        Expect(err).ToNot(HaveOccurred())
+
+        rows, err := tx.Queryx("SELECT")
+        Expect(err).ToNot(HaveOccurred())
+
+        at, err := NewAliasedTables()
+        Expect(err).ToNot(HaveOccurred())
+        qr := NewQueryResults(
+            tx, rows, at, map[reflect.Type]*refl.GroupedFieldsWithBS{},
+        )
+
+        var results []nameTotal
+        for qr.Next() {
+            result := nameTotal{}
+            Expect(qr.ScanStruct(&result)).To(Succeed())
+            results = append(results, result)
+        }
+        Expect(qr.Err()).ToNot(HaveOccurred())
+        Expect(qr.Close()).To(Succeed())
+        Expect(results).To(Equal([]nameTotal{
+            {Name: "foo", Total: 3},
+            {Name: "bar"},
+        }))
+    })
+    It("should be able to write all results as maps", func() {
+        expectedRows := sqlmock.NewRows(
+            []string{"name", "total"},
+        ).AddRow("foo", 3).AddRow("bar", nil)
+        mock.ExpectBegin()
+        mock.ExpectQuery("SELECT").WillReturnRows(expectedRows)
+        mock.ExpectCommit()
+        tx, err := dbx.Beginx()
+        Expect(err).ToNot(HaveOccurred())
+
+        rows, err := tx.Queryx("SELECT")
+        Expect(err).ToNot(HaveOccurred())
+
+        at, err := NewAliasedTables()
+        Expect(err).ToNot(HaveOccurred())
+        qr := NewQueryResults(
+            tx, rows, at, map[reflect.Type]*refl.GroupedFieldsWithBS{},
+        )
+
+        var results []map[string]interface{}
+        Expect(qr.WriteAllTo(&results)).To(Succeed())
+        Expect(results).To(HaveLen(2))
+        Expect(results[0]).To(HaveKeyWithValue("name", "foo"))
+        Expect(results[0]).To(HaveKeyWithValue("total", BeEquivalentTo(3)))
+        Expect(results[1]).To(HaveKeyWithValue("total", BeNil()))
+        Expect(mock.ExpectationsWereMet()).To(Succeed())
+    })
 })
@@ -38,6 +38,19 @@ func (self LiteralQueryable) QueryValue(
     return self.Value, nil
 }
 
+// NullQueryable is the sql NULL value.
+type NullQueryable struct{}
+
+func (self NullQueryable) String() string {
+    return "NULL"
+}
+
+func (self NullQueryable) QueryValue(
+    *AliasedTables,
+) (string, []interface{}) {
+    return "NULL", nil
+}
+
 // ConstantQueryable is a value or series of values such as numbers or strings
 // that will be used in a statement.
 type ConstantQueryable struct {
@@ -99,21 +112,34 @@ func (self ConstantQueryable) QueryValue(
 }
 
 // TableColumnQueryable is a table/column pairing that will be used in a query.
-// It will automatically be aliased as appropriate by the caller.
+// It will automatically be aliased as appropriate by the caller. If
+// TableAlias is set it's used as is; otherwise if Object is set the alias for
+// that specific object is used before falling back on the table's alias.
 type TableColumnQueryable struct {
     TableName,
+    TableAlias,
     ColumnName string
+    Object base.Base
 }
 
 func (self TableColumnQueryable) String() string {
+    if self.TableAlias != "" {
+        return fmt.Sprintf("%s.%s", self.TableAlias, self.ColumnName)
+    }
     return fmt.Sprintf("%s.%s", self.TableName, self.ColumnName)
 }
 
 func (self TableColumnQueryable) QueryValue(
     at *AliasedTables,
 ) (string, []interface{}) {
+    if self.TableAlias != "" {
+        return fmt.Sprintf("%s.%s", self.TableAlias, self.ColumnName), nil
+    }
+
     tableName := self.TableName
-    if tableAlias, ok := at.AliasForTable(self.TableName); ok {
+    if self.Object != nil && at.ObjectIsAliased(self.Object) {
+        tableName, _ = at.ObjectAlias(self.Object)
+    } else if tableAlias, ok := at.AliasForTable(self.TableName); ok {
         tableName = tableAlias
     }
 
@@ -197,7 +223,7 @@ func InterfaceToQueryable(in interface{}) Queryable {
 // ObjectColumn is a convinience function that atomatically grabs the provided
 // objects column and prefixes the provided column with it.
 func ObjectColumn(obj base.Base, column string) (Queryable, error) {
-    tableName, err := base.BaseTable(obj)
+    tableName, err := base.BaseTable(UnaliasedObject(obj))
     if err != nil {
         return nil, errors.Wrap(err, "Couldn't get object table for queryable")
     }
@@ -205,5 +231,15 @@ func ObjectColumn(obj base.Base, column string) (Queryable, error) {
     return TableColumnQueryable{
         TableName: tableName,
         ColumnName: column,
+        Object: obj,
     }, nil
 }
+
+// AliasColumn creates a Queryable for a column of the table with the provided
+// alias.
+func AliasColumn(alias, column string) Queryable {
+    return TableColumnQueryable{
+        TableAlias: alias,
+        ColumnName: column,
+    }
+}
@@ -0,0 +1,122 @@
+package qtypes
+
+import (
+    "strings"
+
+    "github.com/pkg/errors"
+)
+
+// RawQueryable is a raw SQL fragment with `?` markers which are replaced by
+// the provided arguments. Arguments that are Queryables (such as columns) are
+// rendered as they normally would be while any other value becomes a bind
+// argument. A literal `?` (such as the Postgres jsonb `?` operators) can be
+// written as `??` and any `?` inside a quoted string or identifier is left
+// alone. Colons are escaped when the fragment is rendered for a query so they
+// can be written as is.
+type RawQueryable struct {
+    SQL string
+    Args []interface{}
+}
+
+// splitRaw splits the SQL of the fragment at each `?` marker.
+func (self RawQueryable) splitRaw() []string {
+    parts := []string{""}
+    var quote byte
+    for i := 0; i < len(self.SQL); i++ {
+        char := self.SQL[i]
+        switch {
+        case quote != 0:
+            if char == quote {
+                quote = 0
+            }
+        case char == '\'' || char == '"' || char == '`':
+            quote = char
+        case char == '?':
+            if i+1 < len(self.SQL) && self.SQL[i+1] == '?' {
+                i++
+            } else {
+                parts = append(parts, "")
+                continue
+            }
+        }
+        parts[len(parts)-1] += string(char)
+    }
+
+    return parts
+}
+
+func (self RawQueryable) validate(parts []string) error {
+    if len(parts) - 1 != len(self.Args) {
+        return errors.Errorf(
+            "Raw SQL '%s' has %d markers but %d arguments were provided",
+            self.SQL,
+            len(parts) - 1,
+            len(self.Args),
+        )
+    }
+
+    return nil
+}
+
+// evaluate renders the fragment with the provided valuer. When escaping, any
+// `:` in the fragment's own SQL is doubled so that it isn't read as a named
+// argument when the query is bound (such as `::date` casts or times in
+// quoted strings).
+func (self RawQueryable) evaluate(
+    v queryableValuer, escape bool,
+) (string, []interface{}) {
+    parts := self.splitRaw()
+    if self.validate(parts) != nil {
+        if escape {
+            return strings.Replace(self.SQL, ":", "::", -1), nil
+        }
+        return self.SQL, nil
+    }
+
+    var builder strings.Builder
+    var allArgs []interface{}
+    for i, part := range parts {
+        if escape {
+            part = strings.Replace(part, ":", "::", -1)
+        }
+        builder.WriteString(part)
+        if i < len(self.Args) {
+            argString, args := v(InterfaceToQueryable(self.Args[i]))
+            builder.WriteString(argString)
+            allArgs = append(allArgs, args...)
+        }
+    }
+
+    return builder.String(), allArgs
+}
+
+func (self RawQueryable) String() string {
+    queryString, _ := self.evaluate(stringValuer, false)
+    return queryString
+}
+
+// QueryValue renders the fragment. A mismatch between the number of markers
+// and arguments is reported to the provided AliasedTables.
+func (self RawQueryable) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    if err := self.validate(self.splitRaw()); err != nil {
+        at.ReportError(err)
+    }
+
+    return self.evaluate(aliasedTablesValuer(at), true)
+}
+
+// NewRawQueryable creates a RawQueryable checking that there's an argument for
+// every marker.
+func NewRawQueryable(sql string, args ...interface{}) (Queryable, error) {
+    raw := RawQueryable{
+        SQL: sql,
+        Args: args,
+    }
+    if err := raw.validate(raw.splitRaw()); err != nil {
+        return nil, err
+    }
+
+    return raw, nil
+}
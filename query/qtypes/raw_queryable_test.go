@@ -0,0 +1,105 @@
+package qtypes
+
+import (
+    "database/sql"
+
+    "github.com/jmoiron/sqlx"
+
+    . "github.com/onsi/ginkgo"
+    . "github.com/onsi/gomega"
+)
+
+type testObjectRaw struct {
+    Foo string
+}
+
+var _ = Describe("RawQueryable", func() {
+    var aliasedTables *AliasedTables
+    object := &testObjectRaw{}
+    BeforeEach(func() {
+        var err error
+        aliasedTables, err = NewAliasedTables(object)
+        Expect(err).ToNot(HaveOccurred())
+    })
+
+    It("should replace markers with columns and bind arguments", func() {
+        column, err := ObjectColumn(object, "foo")
+        Expect(err).ToNot(HaveOccurred())
+        raw, err := NewRawQueryable("lower(?) = ?", column, "bar")
+        Expect(err).ToNot(HaveOccurred())
+
+        queryString, args := raw.QueryValue(aliasedTables)
+        Expect(args).To(HaveLen(1))
+        namedArg := args[0].(sql.NamedArg)
+        Expect(queryString).To(Equal("lower(a.foo) = :" + namedArg.Name))
+        Expect(namedArg.Value).To(Equal("bar"))
+        Expect(raw.String()).To(
+            Equal("lower(test_object_raws.foo) = 'bar'"),
+        )
+    })
+    It("should allow escaping markers", func() {
+        raw, err := NewRawQueryable("a.foo ?? ?", "bar")
+        Expect(err).ToNot(HaveOccurred())
+
+        queryString, args := raw.QueryValue(aliasedTables)
+        Expect(args).To(HaveLen(1))
+        namedArg := args[0].(sql.NamedArg)
+        Expect(queryString).To(Equal("a.foo ? :" + namedArg.Name))
+        Expect(aliasedTables.ReportedErrors()).To(BeEmpty())
+    })
+    It("should leave markers in quoted strings alone", func() {
+        raw, err := NewRawQueryable(
+            `a.foo ??| array['?', 'it''s?'] AND "b?" = ?`, "bar",
+        )
+        Expect(err).ToNot(HaveOccurred())
+
+        queryString, args := raw.QueryValue(aliasedTables)
+        Expect(args).To(HaveLen(1))
+        namedArg := args[0].(sql.NamedArg)
+        Expect(queryString).To(Equal(
+            `a.foo ?| array['?', 'it''s?'] AND "b?" = :` + namedArg.Name,
+        ))
+        Expect(aliasedTables.ReportedErrors()).To(BeEmpty())
+    })
+    It("should escape colons in the raw SQL", func() {
+        column, err := ObjectColumn(object, "foo")
+        Expect(err).ToNot(HaveOccurred())
+        raw, err := NewRawQueryable(
+            "to_char(?::date, 'HH24:MI') = '10:30' AND ? = ?",
+            column,
+            column,
+            "bar",
+        )
+        Expect(err).ToNot(HaveOccurred())
+
+        queryString, args := raw.QueryValue(aliasedTables)
+        Expect(args).To(HaveLen(1))
+        namedArg := args[0].(sql.NamedArg)
+        Expect(queryString).To(Equal(
+            "to_char(a.foo::::date, 'HH24::MI') = '10::30' AND a.foo = :" +
+                namedArg.Name,
+        ))
+
+        bound, boundArgs, err := sqlx.Named(
+            queryString,
+            map[string]interface{}{namedArg.Name: namedArg.Value},
+        )
+        Expect(err).ToNot(HaveOccurred())
+        Expect(bound).To(Equal(
+            "to_char(a.foo::date, 'HH24:MI') = '10:30' AND a.foo = ?",
+        ))
+        Expect(boundArgs).To(Equal([]interface{}{"bar"}))
+        Expect(raw.String()).To(Equal(
+            "to_char(test_object_raws.foo::date, 'HH24:MI') = '10:30' " +
+                "AND test_object_raws.foo = 'bar'",
+        ))
+    })
+    It("should error when the arguments don't match the markers", func() {
+        _, err := NewRawQueryable("? = ?", 1)
+        Expect(err).To(HaveOccurred())
+
+        raw := RawQueryable{SQL: "?"}
+        raw.QueryValue(aliasedTables)
+        Expect(aliasedTables.ReportedErrors()).To(HaveLen(1))
+    })
+})
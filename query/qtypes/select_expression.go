@@ -2,6 +2,8 @@ package qtypes
 
 import (
     "github.com/daihasso/beagle"
+
+    "github.com/daihasso/machgo/base"
 )
 
 var tableColumnRegex = beagle.MustRegex(
@@ -9,11 +11,15 @@ var tableColumnRegex = beagle.MustRegex(
 )
 
 // SelectExpression represents a column/table pairing for use in a select
-// statement.
+// statement. It may instead wrap an arbitrary Queryable (such as a
+// SelectFunction) optionally given an alias.
 type SelectExpression struct {
     withTable bool
     columnName,
     tableName string
+    object base.Base
+    expression Queryable
+    alias string
 }
 
 func (self SelectExpression) Table() (string, bool) {
@@ -28,6 +34,24 @@ func (self SelectExpression) Column() string {
     return self.columnName
 }
 
+// Expression retrieves the Queryable this expression selects if it was
+// created from one.
+func (self SelectExpression) Expression() (Queryable, bool) {
+    return self.expression, self.expression != nil
+}
+
+// Alias is the name given to the selected value; it's empty when no alias
+// was given.
+func (self SelectExpression) Alias() string {
+    return self.alias
+}
+
+// Object retrieves the object this expression selects from if it was created
+// from one.
+func (self SelectExpression) Object() (base.Base, bool) {
+    return self.object, self.object != nil
+}
+
 // NewSelectExpression takes an expression in the format `a.b` and turns it
 // into a SelectExpression.
 func NewSelectExpression(exp string) SelectExpression {
@@ -45,3 +69,15 @@ func NewSelectExpression(exp string) SelectExpression {
         tableName: "",
     }
 }
+
+// NewQueryableSelectExpression creates a SelectExpression which selects the
+// provided Queryable under the provided alias (which may be empty).
+func NewQueryableSelectExpression(
+    expression Queryable, alias string,
+) SelectExpression {
+    return SelectExpression{
+        columnName: alias,
+        expression: expression,
+        alias: alias,
+    }
+}
@@ -10,6 +10,11 @@ type FunctionType int
 const (
     UnsetFunctionType = iota
     CountFunctionType
+    CountDistinctFunctionType
+    SumFunctionType
+    AvgFunctionType
+    MinFunctionType
+    MaxFunctionType
 )
 
 // SelectFunction is a special type of Queryable that invokes a SQL function.
@@ -52,3 +57,120 @@ func (self SelectCount) QueryValue(
     }
     return self.fmtString(str), vars
 }
+
+func functionQueryValue(
+    format string, expression Queryable, at *AliasedTables,
+) (string, []interface{}) {
+    var (
+        str string
+        vars []interface{}
+    )
+    if expression != nil {
+        str, vars = expression.QueryValue(at)
+    }
+    return fmt.Sprintf(format, str), vars
+}
+
+func functionString(format string, expression Queryable) string {
+    str := ""
+    if expression != nil {
+        str = expression.String()
+    }
+    return fmt.Sprintf(format, str)
+}
+
+// SelectCountDistinct uses the SQL COUNT function counting only distinct
+// values of the provided Expression.
+type SelectCountDistinct struct {
+    Expression Queryable
+}
+
+func (SelectCountDistinct) FunctionType() FunctionType {
+    return CountDistinctFunctionType
+}
+
+func (self SelectCountDistinct) String() string {
+    return functionString("COUNT(DISTINCT %s)", self.Expression)
+}
+
+func (self SelectCountDistinct) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    return functionQueryValue("COUNT(DISTINCT %s)", self.Expression, at)
+}
+
+// SelectSum uses the SQL SUM function with the provided Expression.
+type SelectSum struct {
+    Expression Queryable
+}
+
+func (SelectSum) FunctionType() FunctionType {
+    return SumFunctionType
+}
+
+func (self SelectSum) String() string {
+    return functionString("SUM(%s)", self.Expression)
+}
+
+func (self SelectSum) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    return functionQueryValue("SUM(%s)", self.Expression, at)
+}
+
+// SelectAvg uses the SQL AVG function with the provided Expression.
+type SelectAvg struct {
+    Expression Queryable
+}
+
+func (SelectAvg) FunctionType() FunctionType {
+    return AvgFunctionType
+}
+
+func (self SelectAvg) String() string {
+    return functionString("AVG(%s)", self.Expression)
+}
+
+func (self SelectAvg) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    return functionQueryValue("AVG(%s)", self.Expression, at)
+}
+
+// SelectMin uses the SQL MIN function with the provided Expression.
+type SelectMin struct {
+    Expression Queryable
+}
+
+func (SelectMin) FunctionType() FunctionType {
+    return MinFunctionType
+}
+
+func (self SelectMin) String() string {
+    return functionString("MIN(%s)", self.Expression)
+}
+
+func (self SelectMin) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    return functionQueryValue("MIN(%s)", self.Expression, at)
+}
+
+// SelectMax uses the SQL MAX function with the provided Expression.
+type SelectMax struct {
+    Expression Queryable
+}
+
+func (SelectMax) FunctionType() FunctionType {
+    return MaxFunctionType
+}
+
+func (self SelectMax) String() string {
+    return functionString("MAX(%s)", self.Expression)
+}
+
+func (self SelectMax) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    return functionQueryValue("MAX(%s)", self.Expression, at)
+}
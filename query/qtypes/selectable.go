@@ -14,13 +14,16 @@ type Selectable func() (SelectExpression, error)
 // BaseSelectable takes a base an provides a Selectable from it.
 func BaseSelectable(obj base.Base) Selectable {
     return func() (SelectExpression, error) {
-        tableName, err := base.BaseTable(obj)
+        tableName, err := base.BaseTable(UnaliasedObject(obj))
         if err != nil {
             return SelectExpression{}, errors.Wrapf(
                 err, "Unable to get table from object '%#+v'", obj,
             )
         }
-        return NewSelectExpression(fmt.Sprintf("%s.*", tableName)), nil
+        expression := NewSelectExpression(fmt.Sprintf("%s.*", tableName))
+        expression.object = obj
+
+        return expression, nil
     }
 }
 
@@ -31,3 +34,49 @@ func LiteralSelectable(exp string) Selectable {
         return NewSelectExpression(exp), nil
     }
 }
+
+// ExpressionSelectable selects the provided Queryable as the provided alias.
+// The alias is how the value is named in the results; it may be empty.
+func ExpressionSelectable(expression Queryable, alias string) Selectable {
+    return func() (SelectExpression, error) {
+        if expression == nil {
+            return SelectExpression{}, errors.New(
+                "Can't select a nil expression",
+            )
+        }
+        return NewQueryableSelectExpression(expression, alias), nil
+    }
+}
+
+// CountSelectable selects the count of the provided expression as alias.
+func CountSelectable(expression Queryable, alias string) Selectable {
+    return ExpressionSelectable(SelectCount{Expression: expression}, alias)
+}
+
+// CountDistinctSelectable selects the count of distinct values of the
+// provided expression as alias.
+func CountDistinctSelectable(expression Queryable, alias string) Selectable {
+    return ExpressionSelectable(
+        SelectCountDistinct{Expression: expression}, alias,
+    )
+}
+
+// SumSelectable selects the sum of the provided expression as alias.
+func SumSelectable(expression Queryable, alias string) Selectable {
+    return ExpressionSelectable(SelectSum{Expression: expression}, alias)
+}
+
+// AvgSelectable selects the average of the provided expression as alias.
+func AvgSelectable(expression Queryable, alias string) Selectable {
+    return ExpressionSelectable(SelectAvg{Expression: expression}, alias)
+}
+
+// MinSelectable selects the minimum of the provided expression as alias.
+func MinSelectable(expression Queryable, alias string) Selectable {
+    return ExpressionSelectable(SelectMin{Expression: expression}, alias)
+}
+
+// MaxSelectable selects the maximum of the provided expression as alias.
+func MaxSelectable(expression Queryable, alias string) Selectable {
+    return ExpressionSelectable(SelectMax{Expression: expression}, alias)
+}
@@ -42,4 +42,24 @@ var _ = Describe("Selectable", func() {
         Expect(ok).To(BeFalse())
         Expect(selectExp.Column()).To(Equal("a"))
     })
+    It("should be createable from an aggregate with an alias", func() {
+        aliasedTables, err := NewAliasedTables(&testObjectSelectable{})
+        Expect(err).ToNot(HaveOccurred())
+        selectable := AvgSelectable(AliasColumn("a", "foo"), "average")
+
+        selectExp, err := selectable()
+        Expect(err).ToNot(HaveOccurred())
+
+        expression, ok := selectExp.Expression()
+        Expect(ok).To(BeTrue())
+        queryString, args := expression.QueryValue(aliasedTables)
+        Expect(queryString).To(Equal("AVG(a.foo)"))
+        Expect(args).To(BeEmpty())
+        Expect(selectExp.Alias()).To(Equal("average"))
+        Expect(selectExp.Column()).To(Equal("average"))
+    })
+    It("should not be createable from a nil expression", func() {
+        _, err := ExpressionSelectable(nil, "foo")()
+        Expect(err).To(HaveOccurred())
+    })
 })
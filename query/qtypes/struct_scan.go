@@ -0,0 +1,135 @@
+package qtypes
+
+import (
+    "reflect"
+    "strings"
+
+    "github.com/jmoiron/sqlx"
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/refl"
+)
+
+// structColumnFields maps column names to the index of the field of the
+// provided struct type they should be written to. Fields are matched by their
+// `db` tag or otherwise by their name being the UpperCamelCase of the column.
+func structColumnFields(typ reflect.Type) map[string][]int {
+    columnFields := make(map[string][]int)
+    for _, field := range reflect.VisibleFields(typ) {
+        if field.Anonymous || field.PkgPath != "" {
+            continue
+        }
+        if throughPointer(typ, field.Index) {
+            continue
+        }
+
+        column := ""
+        if tag, ok := field.Tag.Lookup("db"); ok {
+            column = strings.Split(tag, ",")[0]
+            if column == "-" {
+                continue
+            }
+        }
+        if column == "" {
+            column = field.Name
+        }
+        if _, ok := columnFields[column]; !ok {
+            columnFields[column] = field.Index
+        }
+    }
+
+    return columnFields
+}
+
+// throughPointer checks if reaching the field at the provided index requires
+// going through an embedded pointer.
+func throughPointer(typ reflect.Type, index []int) bool {
+    for _, i := range index[:len(index)-1] {
+        field := typ.Field(i)
+        if field.Type.Kind() == reflect.Ptr {
+            return true
+        }
+        typ = field.Type
+    }
+
+    return false
+}
+
+// StructScanner writes rows into plain structs which don't need to be models
+// or use the alias_column naming scheme.
+type StructScanner struct {
+    typ reflect.Type
+    columns []string
+    fieldIndexes [][]int
+}
+
+// Scan reads the current row of the provided rows into dest which must be an
+// addressable struct value of the scanner's type.
+func (self StructScanner) Scan(rows *sqlx.Rows, dest reflect.Value) error {
+    if dest.Type() != self.typ || !dest.CanAddr() {
+        return errors.Errorf(
+            "Can't scan into %s, expected an addressable %s",
+            dest.Type(),
+            self.typ,
+        )
+    }
+
+    targets := make([]*nullableScanTarget, len(self.columns))
+    values := make([]interface{}, len(self.columns))
+    for i := range self.columns {
+        targets[i] = &nullableScanTarget{}
+        values[i] = targets[i]
+    }
+
+    err := rows.Scan(values...)
+    if err != nil {
+        return err
+    }
+
+    for i, target := range targets {
+        field := dest.FieldByIndex(self.fieldIndexes[i])
+        if target.value == nil {
+            field.Set(reflect.Zero(field.Type()))
+            continue
+        }
+        err := refl.InitSetField(field, reflect.ValueOf(target.value))
+        if err != nil {
+            return errors.Wrapf(
+                err, "Error setting column '%s'", self.columns[i],
+            )
+        }
+    }
+
+    return nil
+}
+
+// NewStructScanner creates a StructScanner for the provided struct type and
+// result columns. Every column must have a field to be written to.
+func NewStructScanner(
+    typ reflect.Type, columns []string,
+) (*StructScanner, error) {
+    if typ.Kind() != reflect.Struct {
+        return nil, errors.Errorf("Can't scan into non-struct type %s", typ)
+    }
+
+    columnFields := structColumnFields(typ)
+    fieldIndexes := make([][]int, len(columns))
+    for i, column := range columns {
+        index, ok := columnFields[column]
+        if !ok {
+            index, ok = columnFields[SnakeToUpperCamel(column)]
+        }
+        if !ok {
+            return nil, errors.Errorf(
+                "No field in %s for column '%s'", typ, column,
+            )
+        }
+        fieldIndexes[i] = index
+    }
+
+    return &StructScanner{
+        typ: typ,
+        columns: columns,
+        fieldIndexes: fieldIndexes,
+    }, nil
+}
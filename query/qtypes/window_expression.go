@@ -0,0 +1,137 @@
+package qtypes
+
+import (
+    "fmt"
+    "strings"
+)
+
+// FrameBound is the start or end of the frame of rows a window function is
+// evaluated over.
+type FrameBound string
+
+// Definitions of the fixed frame bounds.
+const (
+    UnboundedPreceding FrameBound = "UNBOUNDED PRECEDING"
+    CurrentRow FrameBound = "CURRENT ROW"
+    UnboundedFollowing FrameBound = "UNBOUNDED FOLLOWING"
+)
+
+// Preceding is a frame bound the provided number of rows before the current
+// row.
+func Preceding(rows int) FrameBound {
+    return FrameBound(fmt.Sprintf("%d PRECEDING", rows))
+}
+
+// Following is a frame bound the provided number of rows after the current
+// row.
+func Following(rows int) FrameBound {
+    return FrameBound(fmt.Sprintf("%d FOLLOWING", rows))
+}
+
+// WindowExpression evaluates a function (such as ROW_NUMBER() or an aggregate
+// like SelectSum) over a window of rows in the fashion of
+// `ROW_NUMBER() OVER (PARTITION BY a.post_id ORDER BY a.created DESC)`.
+// Its methods return modified copies so they can be chained.
+type WindowExpression struct {
+    Function Queryable
+    Partition []Queryable
+    Order []Queryable
+    Frame string
+}
+
+// PartitionBy adds expressions to split the rows into separate windows by.
+func (self WindowExpression) PartitionBy(
+    expressions ...Queryable,
+) WindowExpression {
+    self.Partition = append(
+        append([]Queryable(nil), self.Partition...), expressions...,
+    )
+    return self
+}
+
+// OrderBy adds expressions to order the rows of each window by.
+func (self WindowExpression) OrderBy(
+    expressions ...Queryable,
+) WindowExpression {
+    self.Order = append(
+        append([]Queryable(nil), self.Order...), expressions...,
+    )
+    return self
+}
+
+// Rows sets the frame to the rows between start and end.
+func (self WindowExpression) Rows(start, end FrameBound) WindowExpression {
+    self.Frame = fmt.Sprintf("ROWS BETWEEN %s AND %s", start, end)
+    return self
+}
+
+// Range sets the frame to the rows with values between start and end.
+func (self WindowExpression) Range(start, end FrameBound) WindowExpression {
+    self.Frame = fmt.Sprintf("RANGE BETWEEN %s AND %s", start, end)
+    return self
+}
+
+// As selects the window expression as the provided alias.
+func (self WindowExpression) As(alias string) Selectable {
+    return ExpressionSelectable(self, alias)
+}
+
+func (self WindowExpression) evaluate(
+    v queryableValuer,
+) (string, []interface{}) {
+    functionString, args := v(self.Function)
+
+    var clauses []string
+    if len(self.Partition) != 0 {
+        partitionString, partitionArgs := v(
+            NewMultiListCondition(self.Partition...),
+        )
+        clauses = append(clauses, "PARTITION BY " + partitionString)
+        args = append(args, partitionArgs...)
+    }
+    if len(self.Order) != 0 {
+        orderString, orderArgs := v(NewMultiListCondition(self.Order...))
+        clauses = append(clauses, "ORDER BY " + orderString)
+        args = append(args, orderArgs...)
+    }
+    if self.Frame != "" {
+        clauses = append(clauses, self.Frame)
+    }
+
+    return fmt.Sprintf(
+        "%s OVER (%s)", functionString, strings.Join(clauses, " "),
+    ), args
+}
+
+func (self WindowExpression) String() string {
+    queryString, _ := self.evaluate(stringValuer)
+    return queryString
+}
+
+func (self WindowExpression) QueryValue(
+    at *AliasedTables,
+) (string, []interface{}) {
+    return self.evaluate(aliasedTablesValuer(at))
+}
+
+// Over creates a WindowExpression evaluating the provided function.
+func Over(function Queryable) WindowExpression {
+    return WindowExpression{
+        Function: function,
+    }
+}
+
+// RowNumber numbers the rows of each window starting at 1.
+func RowNumber() WindowExpression {
+    return Over(LiteralQueryable{Value: "ROW_NUMBER()"})
+}
+
+// Rank ranks the rows of each window leaving gaps after ties.
+func Rank() WindowExpression {
+    return Over(LiteralQueryable{Value: "RANK()"})
+}
+
+// DenseRank ranks the rows of each window without leaving gaps after ties.
+func DenseRank() WindowExpression {
+    return Over(LiteralQueryable{Value: "DENSE_RANK()"})
+}
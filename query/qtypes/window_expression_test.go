@@ -0,0 +1,63 @@
+package qtypes
+
+import (
+    . "github.com/onsi/ginkgo"
+    . "github.com/onsi/gomega"
+)
+
+type testObjectWindow struct {
+    PostId int64
+    Created int64
+    Amount int64
+}
+
+var _ = Describe("WindowExpression", func() {
+    var aliasedTables *AliasedTables
+    object := &testObjectWindow{}
+    BeforeEach(func() {
+        var err error
+        aliasedTables, err = NewAliasedTables(object)
+        Expect(err).ToNot(HaveOccurred())
+    })
+
+    It("should render partitions and ordering", func() {
+        postId, err := ObjectColumn(object, "post_id")
+        Expect(err).ToNot(HaveOccurred())
+        created, err := ObjectColumn(object, "created")
+        Expect(err).ToNot(HaveOccurred())
+
+        window := RowNumber().PartitionBy(postId).OrderBy(
+            DescendingQueryable{Statement: created},
+        )
+        queryString, args := window.QueryValue(aliasedTables)
+        Expect(queryString).To(Equal(
+            "ROW_NUMBER() OVER (PARTITION BY a.post_id " +
+                "ORDER BY a.created DESC)",
+        ))
+        Expect(args).To(BeEmpty())
+    })
+    It("should render frames for aggregates", func() {
+        amount, err := ObjectColumn(object, "amount")
+        Expect(err).ToNot(HaveOccurred())
+        created, err := ObjectColumn(object, "created")
+        Expect(err).ToNot(HaveOccurred())
+
+        window := Over(SelectSum{Expression: amount}).OrderBy(created).Rows(
+            UnboundedPreceding, CurrentRow,
+        )
+        queryString, _ := window.QueryValue(aliasedTables)
+        Expect(queryString).To(Equal(
+            "SUM(a.amount) OVER (ORDER BY a.created " +
+                "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)",
+        ))
+    })
+    It("should not modify the expression it was built from", func() {
+        amount, err := ObjectColumn(object, "amount")
+        Expect(err).ToNot(HaveOccurred())
+        base := Rank().PartitionBy(amount)
+        base.PartitionBy(amount).Range(Preceding(1), Following(2))
+
+        Expect(base.Partition).To(HaveLen(1))
+        Expect(base.Frame).To(BeEmpty())
+    })
+})
@@ -7,11 +7,13 @@ import (
     "sort"
     "strings"
    
+    "github.com/jmoiron/sqlx"
     "github.com/pkg/errors"
 
     "github.com/daihasso/machgo/refl"
     "github.com/daihasso/machgo/query/qtypes"
     "github.com/daihasso/machgo/pool"
+    "github.com/daihasso/machgo/pool/dbtype"
     "github.com/daihasso/machgo/base"
 )
 
@@ -48,6 +50,22 @@ type Query struct {
     typeBSFieldMap,
     typeFieldNameBSFieldMap map[reflect.Type]*refl.GroupedFieldsWithBS
     joinedObjects []base.Base
+    joinTypes map[string]qtypes.JoinType
+    joinConditions map[string]qtypes.Queryable
+    joinVia map[string]string
+    intermediateAliases map[string]string
+    distinct bool
+    distinctOn []qtypes.Queryable
+    fromSubquery *Subquery
+    fromSubqueryAlias string
+    commonTableExpressions []commonTableExpression
+    setOperations []setOperation
+    keyset *keysetCursor
+    // tx is the caller's transaction the query runs in if it has one.
+    tx *sqlx.Tx
+    // plainColumns selects object columns by their own names rather than
+    // the alias_column naming scheme.
+    plainColumns bool
 
     cached cachedQuery
 
@@ -69,23 +87,47 @@ func (self *Query) addObjects(objects []base.Base) error {
 
 func (self *Query) cacheTagsForType(objects []base.Base) {
     for _, object := range objects {
-        objType := refl.Deref(reflect.TypeOf(object))
-        fieldGroupings := refl.GetGroupedFieldsWithBS(
-            object,
-            refl.GroupFieldsByTagValue("db", "dbfkey"),
-            refl.GroupFieldsByFieldName(),
-        )
-        tagValBSFields := fieldGroupings[0]
-        fieldNameBSFields := fieldGroupings[1]
+        descriptor, err := base.DescriptorFor(qtypes.UnaliasedObject(object))
+        if err != nil {
+            continue
+        }
+        objType := descriptor.Type()
 
-        self.typeBSFieldMap[objType] = tagValBSFields
-        self.typeFieldNameBSFieldMap[objType] = fieldNameBSFields
+        self.typeBSFieldMap[objType] = descriptor.ColumnBSFields()
+        self.typeFieldNameBSFieldMap[objType] = descriptor.FieldNameBSFields()
     }
 }
 
-// Join adds an object to the query. The joined object should have a
-// Relationship with at least one other object already in the Query.
-func (self *Query) Join(objects ...base.Base) *Query {
+// As gives the provided object an explicit alias. This allows the same table
+// to be included in a query more than once such as for self-joins. The result
+// can be used anywhere the object would be (Join, JoinOn, ObjectColumn,
+// BaseSelectable) as well as to wrap a slice when writing results. The alias
+// is lowercased to match how the database returns it.
+func As(object base.Base, alias string) *qtypes.AliasedObject {
+    return qtypes.Aliased(object, alias)
+}
+
+// CTE refers to the common table expression with the provided name (added
+// with With or WithRecursive) as a table of the provided object's type. The
+// result can be joined and selected like the object itself.
+func CTE(object base.Base, name string) *qtypes.AliasedObject {
+    return qtypes.InTable(object, name)
+}
+
+func (self *Query) join(
+    joinType qtypes.JoinType, objects []base.Base,
+) *Query {
+    self.addJoinedObjects(joinType, objects)
+    self.addIntermediateTables()
+
+    return self
+}
+
+// addJoinedObjects adds the provided objects to the query with the provided
+// type of join.
+func (self *Query) addJoinedObjects(
+    joinType qtypes.JoinType, objects []base.Base,
+) {
     self.cached.Select.invalidate()
     self.cached.From.invalidate()
 
@@ -93,8 +135,159 @@ func (self *Query) Join(objects ...base.Base) *Query {
     if err != nil {
         wrapped := errors.Wrap(err, "Error while adding objects to join")
         self.Errors = append(self.Errors, wrapped)
+        return
+    }
+
+    for _, object := range objects {
+        alias, err := self.Tables.ObjectAlias(object)
+        if err != nil {
+            continue
+        }
+        self.joinTypes[alias] = joinType
+    }
+}
+
+// Join adds an object to the query. The joined object should have a
+// Relationship with at least one other object already in the Query or be
+// reachable through the relationships of registered link models in which case
+// the tables in between are joined automatically but not selected. Only
+// models registered with base.RegisterLinkModels before Join is called are
+// used this way; the relationships of other models which weren't joined are
+// never followed.
+func (self *Query) Join(objects ...base.Base) *Query {
+    return self.join(qtypes.InnerJoinType, objects)
+}
+
+// LeftJoin adds an object to the query which is optional; rows without a
+// match will still be returned and the object will be left empty.
+func (self *Query) LeftJoin(objects ...base.Base) *Query {
+    return self.join(qtypes.LeftJoinType, objects)
+}
+
+// RightJoin adds an object to the query which is required; rows of the
+// object without a match in the rest of the query will still be returned and
+// the other objects will be left empty.
+func (self *Query) RightJoin(objects ...base.Base) *Query {
+    return self.join(qtypes.RightJoinType, objects)
+}
+
+// FullJoin adds an object to the query where either side of the join may be
+// missing.
+func (self *Query) FullJoin(objects ...base.Base) *Query {
+    return self.join(qtypes.FullJoinType, objects)
+}
+
+func (self *Query) joinOn(
+    joinType qtypes.JoinType,
+    object base.Base,
+    condition qtypes.Queryable,
+) *Query {
+    if condition == nil {
+        self.Errors = append(
+            self.Errors, errors.New("JoinOn requires a join condition."),
+        )
+        return self
+    }
+
+    errorCount := len(self.Errors)
+    self.addJoinedObjects(joinType, []base.Base{object})
+    if len(self.Errors) != errorCount {
+        return self
+    }
+
+    alias, err := self.Tables.ObjectAlias(object)
+    if err != nil {
+        return self
+    }
+    self.joinConditions[alias] = condition
+    self.addIntermediateTables()
+
+    return self
+}
+
+// JoinOn adds an object to the query joined using the provided condition
+// rather than a Relationship. This allows for things like multi-column joins
+// or joins with extra filters.
+func (self *Query) JoinOn(
+    object base.Base, condition qtypes.Queryable,
+) *Query {
+    return self.joinOn(qtypes.InnerJoinType, object, condition)
+}
+
+// LeftJoinOn is like JoinOn but the object is optional in the same fashion
+// as LeftJoin.
+func (self *Query) LeftJoinOn(
+    object base.Base, condition qtypes.Queryable,
+) *Query {
+    return self.joinOn(qtypes.LeftJoinType, object, condition)
+}
+
+// RightJoinOn is like JoinOn but the rest of the query is optional in the
+// same fashion as RightJoin.
+func (self *Query) RightJoinOn(
+    object base.Base, condition qtypes.Queryable,
+) *Query {
+    return self.joinOn(qtypes.RightJoinType, object, condition)
+}
+
+// FullJoinOn is like JoinOn but either side of the join may be missing in the
+// same fashion as FullJoin.
+func (self *Query) FullJoinOn(
+    object base.Base, condition qtypes.Queryable,
+) *Query {
+    return self.joinOn(qtypes.FullJoinType, object, condition)
+}
+
+// JoinVia adds an object to the query joined using the Relationship with the
+// provided name. This is required when there are several relationships
+// between the object and the rest of the query.
+func (self *Query) JoinVia(name string, object base.Base) *Query {
+    errorCount := len(self.Errors)
+    self.addJoinedObjects(qtypes.InnerJoinType, []base.Base{object})
+    if len(self.Errors) != errorCount {
+        return self
+    }
+
+    alias, err := self.Tables.ObjectAlias(object)
+    if err != nil {
+        return self
+    }
+    self.joinVia[alias] = name
+    self.addIntermediateTables()
+
+    return self
+}
+
+// FromSubquery selects from the results of the provided subquery as a
+// derived table with the provided alias. Its columns can be referenced with
+// qtypes.AliasColumn and other objects can be joined onto it with JoinOn.
+func (self *Query) FromSubquery(subquery *Subquery, alias string) *Query {
+    self.cached.Select.invalidate()
+    self.cached.From.invalidate()
+
+    if subquery == nil {
+        self.Errors = append(
+            self.Errors, errors.New("Can't select from a nil subquery"),
+        )
+        return self
+    }
+    if self.fromSubquery != nil {
+        self.Errors = append(self.Errors, errors.New(
+            "Only one subquery can be selected from",
+        ))
+        return self
+    }
+    err := self.Tables.AddDerivedTable(alias)
+    if err != nil {
+        self.Errors = append(self.Errors, errors.Wrap(
+            err, "Error while adding subquery to query",
+        ))
+        return self
     }
 
+    self.fromSubquery = subquery
+    self.fromSubqueryAlias = alias
+
     return self
 }
 
@@ -129,6 +322,42 @@ func (self *Query) Select(stmnts ...qtypes.Selectable) *Query {
     return self
 }
 
+// Distinct removes duplicate rows from the results of the query.
+func (self *Query) Distinct() *Query {
+    self.cached.Select.invalidate()
+
+    self.distinct = true
+
+    return self
+}
+
+// DistinctOn keeps only the first row for each distinct value of the provided
+// expressions. Which row is first is determined by the ordering of the query
+// so it should start with the same expressions. This is only supported by
+// Postgres. Repeated calls to this function will append to the existing
+// expressions.
+func (self *Query) DistinctOn(expressions ...qtypes.Queryable) *Query {
+    self.cached.Select.invalidate()
+
+    if self.Pool != nil && self.Pool.Type != dbtype.Postgres {
+        self.Errors = append(self.Errors, errors.Errorf(
+            "DISTINCT ON is only supported by postgres not '%s'",
+            self.Pool.Type,
+        ))
+        return self
+    }
+    if len(expressions) == 0 {
+        self.Errors = append(self.Errors, errors.New(
+            "DISTINCT ON requires at least one expression",
+        ))
+        return self
+    }
+
+    self.distinctOn = append(self.distinctOn, expressions...)
+
+    return self
+}
+
 // Limit sets a limit to the total returned items for the query.
 func (self *Query) Limit(limit int) *Query {
     self.cached.Options.invalidate()
@@ -193,6 +422,52 @@ func (self *Query) OrderBy(orderStatements ...qtypes.Queryable) *Query {
     return self
 }
 
+// GroupBy adds a grouping to the query. Repeated calls to this function will
+// append to the existing grouping.
+func (self *Query) GroupBy(groupStatements ...qtypes.Queryable) *Query {
+    self.cached.Options.invalidate()
+
+    for i, optionClause := range self.OptionClauses {
+        if optionClause.OptionType() == qtypes.GroupByOptionType {
+            groupOption := (self.OptionClauses[i]).(qtypes.GroupByOption)
+            groupOption.AddStatements(groupStatements...)
+            self.OptionClauses[i] = groupOption
+            return self
+        }
+    }
+
+    groupOption := qtypes.GroupByOption{
+        Group: qtypes.NewMultiListCondition(groupStatements...),
+    }
+
+    self.OptionClauses = append(self.OptionClauses, groupOption)
+
+    return self
+}
+
+// Having filters the groups of a query using GroupBy. Repeated calls to this
+// function will require all the conditions to be met.
+func (self *Query) Having(conditions ...qtypes.Queryable) *Query {
+    self.cached.Options.invalidate()
+
+    for i, optionClause := range self.OptionClauses {
+        if optionClause.OptionType() == qtypes.HavingOptionType {
+            havingOption := (self.OptionClauses[i]).(qtypes.HavingOption)
+            havingOption.AddConditions(conditions...)
+            self.OptionClauses[i] = havingOption
+            return self
+        }
+    }
+
+    havingOption := qtypes.HavingOption{
+        Condition: qtypes.NewMultiAndCondition(conditions...),
+    }
+
+    self.OptionClauses = append(self.OptionClauses, havingOption)
+
+    return self
+}
+
 // Order is a convenience wrapper for OrderBy.
 func (self *Query) Order(order ...qtypes.Queryable) *Query {
     return self.OrderBy(order...)
@@ -253,11 +528,6 @@ func (self Query) Results() (*qtypes.QueryResults, error) {
         )
     }
 
-    tx, err := self.Pool.Beginx()
-    if err != nil {
-        return nil, err
-    }
-
     query, variables, err := self.buildQuery()
     if err != nil {
         return nil, errors.Wrap(
@@ -265,28 +535,41 @@ func (self Query) Results() (*qtypes.QueryResults, error) {
         )
     }
 
-    query = self.Pool.Rebind(query)
+    tx, rows, err := self.runQuery(query, variables)
+    if err != nil {
+        return nil, err
+    }
+
+    return self.newResults(tx, rows), nil
+}
 
-    variableMap := make(map[string]interface{}, len(variables))
-    for _, variable := range variables {
-        if namedVar, ok := variable.(sql.NamedArg); ok {
-            variableMap[namedVar.Name] = namedVar.Value
-        }
+// newResults wraps the provided rows of the query in a QueryResults.
+func (self Query) newResults(
+    tx *sqlx.Tx, rows *sqlx.Rows,
+) *qtypes.QueryResults {
+    results := qtypes.NewQueryResults(
+        tx, rows, self.Tables, self.typeBSFieldMap,
+    )
+    results.SetExtraColumns(self.expressionAliases()...)
+    if cursorColumns, ok := self.cursorColumns(); ok {
+        results.SetCursorColumns(cursorColumns...)
     }
 
-    rows, err := tx.NamedQuery(query, variableMap)
-    if err != nil {
-        newErr := tx.Rollback()
-        if newErr != nil {
-            return nil, newErr
-        }
+    return results
+}
 
-        return nil, err
+// expressionAliases retrieves the aliases of the selected expressions. These
+// are never an object's columns even when they look like one (such as
+// `a_total` when `a` is an alias in the query).
+func (self Query) expressionAliases() []string {
+    var aliases []string
+    for _, selectExp := range self.SelectExpressions {
+        if alias := selectExp.Alias(); alias != "" {
+            aliases = append(aliases, alias)
+        }
     }
 
-    return qtypes.NewQueryResults(
-        tx, rows, self.Tables, self.typeBSFieldMap,
-    ), nil
+    return aliases
 }
 
 // Count makes a call to the db to get the total count that would be returned
@@ -299,18 +582,87 @@ func (self Query) Count() (count int, err error) {
         )
     }
 
-    starLiteral := qtypes.LiteralQueryable{
-        Value: "*",
+    query, args, err := self.buildCountQuery()
+    if err != nil {
+        return -1, errors.Wrap(err, "Error while building count query")
     }
-    selectFunc := qtypes.SelectCount{
-        Expression: starLiteral,
+
+    tx, rows, err := self.runQuery(query, args)
+    if err != nil {
+        return -1, err
     }
-    selectString, args := selectFunc.QueryValue(self.Tables)
+    defer func() {
+        err = finishQuery(tx, rows, err)
+        if err != nil {
+            count = -1
+        }
+    }()
 
-    fromString, err := self.buildFrom()
+    if rows.Next() {
+        err = rows.Scan(&count)
+        if err != nil {
+            return -1, err
+        }
+    }
+
+    return count, rows.Err()
+}
+
+// buildCountQuery builds a query counting the rows the query would return
+// ignoring any ordering, limit and offset. Distinct and grouped queries are
+// counted by wrapping them in a subquery unless a single expression is
+// selected distinctly in which case COUNT(DISTINCT ...) is used.
+func (self Query) buildCountQuery() (string, []interface{}, error) {
+    self.Tables.ClearReportedErrors()
+
+    fromString, fromArgs, err := self.buildFrom()
     if err != nil {
-        return -1, errors.Wrap(err, "Error while building from clause")
+        return "", nil, errors.Wrap(err, "Error while building from clause")
     }
+    whereQuery, whereArgs := self.buildWhere()
+    groupingQuery, groupingArgs := self.buildOptionsOfType(
+        qtypes.GroupByOptionType, qtypes.HavingOptionType,
+    )
+
+    var selectString string
+    var args []interface{}
+    subquery := groupingQuery != "" || len(self.distinctOn) != 0 ||
+        len(self.setOperations) != 0
+    if !subquery && self.distinct {
+        subquery = true
+        if len(self.SelectExpressions) == 1 {
+            selectExp := self.SelectExpressions[0]
+            if selectExp.Column() != "*" {
+                value, valueArgs, err := self.selectExpressionValue(
+                    selectExp,
+                )
+                if err != nil {
+                    return "", nil, err
+                }
+                selectFunc := qtypes.SelectCountDistinct{
+                    Expression: qtypes.LiteralQueryable{Value: value},
+                }
+                selectString, _ = selectFunc.QueryValue(self.Tables)
+                args = valueArgs
+                subquery = false
+            }
+        }
+    }
+
+    if subquery {
+        selectString, args, err = self.buildSelect()
+        if err != nil {
+            return "", nil, errors.Wrap(
+                err, "Error while building select clause",
+            )
+        }
+    } else if selectString == "" {
+        selectFunc := qtypes.SelectCount{
+            Expression: qtypes.LiteralQueryable{Value: "*"},
+        }
+        selectString, args = selectFunc.QueryValue(self.Tables)
+    }
+    args = append(args, fromArgs...)
 
     // #nosec G201
     query := fmt.Sprintf(
@@ -318,162 +670,260 @@ func (self Query) Count() (count int, err error) {
         selectString,
         fromString,
     )
-
-    whereQuery, whereArgs := self.buildWhere()
     if whereQuery != "" {
         query += " " + whereQuery
         args = append(args, whereArgs...)
     }
-
-
-    tx, err := self.Pool.Beginx()
-    if err != nil {
-        return -1, err
+    if groupingQuery != "" {
+        query += " " + groupingQuery
+        args = append(args, groupingArgs...)
     }
-
-    query = self.Pool.Rebind(query)
-
-    variableMap := make(map[string]interface{}, len(args))
-    for _, variable := range args {
-        if namedVar, ok := variable.(sql.NamedArg); ok {
-            variableMap[namedVar.Name] = namedVar.Value
+    if len(self.setOperations) != 0 {
+        setQuery, setArgs, err := self.buildSetOperations()
+        if err != nil {
+            return "", nil, err
         }
+        query += setQuery
+        args = append(args, setArgs...)
     }
 
-    rows, err := tx.NamedQuery(query, variableMap)
-    if err != nil {
-        newErr := tx.Rollback()
-        if newErr != nil {
-            return -1, newErr
-        }
-
-        return -1, err
+    if subquery {
+        query = fmt.Sprintf("SELECT COUNT(*) FROM (%s) counted_rows", query)
     }
 
-    defer func() {
-        rows.Close()
-        if err != nil {
-            newErr := tx.Rollback()
-            if newErr != nil {
-                err = newErr
-            }
-            return
-        }
-
-        err = tx.Commit()
-        if err != nil {
-            newErr := tx.Rollback()
-            if newErr != nil {
-                err = newErr
-            }
-        }
-    }()
+    withString, withArgs, err := self.buildWith()
+    if err != nil {
+        return "", nil, err
+    }
+    query = withString + query
+    args = append(withArgs, args...)
 
-    if rows.Next() {
-        err = rows.Scan(&count)
+    if reported := self.Tables.ReportedErrors(); len(reported) != 0 {
+        return "", nil, reported[0]
     }
 
-    return count, nil
+    return query, args, nil
 }
 
 
-func (self Query) buildSelect() (string, error) {
-    if self.cached.Select.valid {
-        return self.cached.Select.query, nil
-    }
+// selectColumn is a single column of the select clause of a query.
+type selectColumn struct {
+    // expression is the value selected such as a.id or COUNT(a.id).
+    expression string
+    // name is the name of the column in the results if it's known.
+    name string
+    // column is the name of the value selected without its table such as id;
+    // it's empty for expressions which weren't given an alias.
+    column string
+    // valueType is the type of the field the column is written to if it's
+    // an object's column.
+    valueType reflect.Type
+    // sql is how the column is written in the select clause.
+    sql string
+}
 
-    var selectString string
+// selectColumns works out the columns the query selects.
+func (self Query) selectColumns() ([]selectColumn, []interface{}, error) {
+    var columns []selectColumn
+    var args []interface{}
     if len(self.SelectExpressions) == 0 {
         allAliases := make([]string, 0)
         for _, alias := range self.Tables.Aliases() {
+            if self.Tables.AliasHidden(alias) {
+                continue
+            }
             allAliases = append(allAliases, alias)
         }
         // Sorting this should make this more easily testable.
         // TODO: Assess performance.
         sort.Strings(allAliases)
         for _, alias := range allAliases {
-            if len(selectString) > 0 {
-                selectString += ", "
+            if self.Tables.AliasDerived(alias) {
+                columns = append(columns, selectColumn{
+                    expression: alias + ".*",
+                    sql: alias + ".*",
+                })
+                continue
             }
-            typ := self.Tables.TypeForAlias(alias)
-            columns, err := self.getSelectableColumns(*typ)
+            aliasColumns, err := self.getSelectableColumns(alias)
             if err != nil {
-                return "", errors.Wrap(
+                return nil, nil, errors.Wrap(
                     err, "Error while trying to get columns for object",
                 )
             }
-            sort.Strings(columns)
-            selectString += strings.Join(columns, ", ")
+            columns = append(columns, aliasColumns...)
         }
-    } else {
-        line := ""
-        for _, selectExp := range self.SelectExpressions {
-            if len(line) != 0 {
-                line += ", "
-            }
 
-            if selectExp.Column() == "*" {
-                tableName, _ := selectExp.Table()
-                typ := self.Tables.TypeForTable(tableName)
-                columns, err := self.getSelectableColumns(*typ)
-                if err != nil {
-                    return "", errors.Wrap(
-                        err, "Error while trying to get columns for object",
-                    )
-                }
-                sort.Strings(columns)
-                line += strings.Join(columns, ", ")
-            } else {
-                if tableName, ok := selectExp.Table(); ok {
-                    alias, ok := self.Tables.AliasForTable(tableName)
-                    if ok {
-                        line += fmt.Sprintf("%s.", alias)
-                    } else {
-                        line += fmt.Sprintf("%s.", tableName)
-                    }
-                }
+        return columns, nil, nil
+    }
 
-                line += selectExp.Column()
+    for _, selectExp := range self.SelectExpressions {
+        if selectExp.Column() == "*" {
+            alias, err := self.selectExpressionAlias(selectExp)
+            if err != nil {
+                return nil, nil, err
             }
+            aliasColumns, err := self.getSelectableColumns(alias)
+            if err != nil {
+                return nil, nil, errors.Wrap(
+                    err, "Error while trying to get columns for object",
+                )
+            }
+            columns = append(columns, aliasColumns...)
+            continue
+        }
+
+        value, valueArgs, err := self.selectExpressionValue(selectExp)
+        if err != nil {
+            return nil, nil, err
+        }
+        column := selectColumn{
+            expression: value,
+            sql: value,
         }
-        selectString += line
+        if alias := selectExp.Alias(); alias != "" {
+            column.name = alias
+            column.column = alias
+            column.sql += " AS " + alias
+        } else if _, ok := selectExp.Expression(); !ok {
+            column.name = selectExp.Column()
+            column.column = selectExp.Column()
+        }
+        columns = append(columns, column)
+        args = append(args, valueArgs...)
     }
 
+    return columns, args, nil
+}
+
+func (self Query) buildSelect() (string, []interface{}, error) {
+    if self.cached.Select.valid {
+        return self.cached.Select.query, self.cached.Select.values, nil
+    }
+
+    selectString, selectArgs := self.buildDistinct()
+    columns, columnArgs, err := self.selectColumns()
+    if err != nil {
+        return "", nil, err
+    }
+    columnStrings := make([]string, len(columns))
+    for i, column := range columns {
+        columnStrings[i] = column.sql
+    }
+    selectString += strings.Join(columnStrings, ", ")
+    selectArgs = append(selectArgs, columnArgs...)
+
     self.cached.Select.query = selectString
+    self.cached.Select.values = selectArgs
     self.cached.Select.valid = true
 
-    return selectString, nil
+    return selectString, selectArgs, nil
 }
 
-func (self Query) buildFrom() (string, error) {
+// buildDistinct builds the DISTINCT or DISTINCT ON prefix for the select
+// clause if there is one.
+func (self Query) buildDistinct() (string, []interface{}) {
+    if len(self.distinctOn) != 0 {
+        expressions := qtypes.NewMultiListCondition(self.distinctOn...)
+        value, args := expressions.QueryValue(self.Tables)
+        return fmt.Sprintf("DISTINCT ON (%s) ", value), args
+    }
+    if self.distinct {
+        return "DISTINCT ", nil
+    }
+
+    return "", nil
+}
+
+// selectExpressionValue renders a select expression which doesn't select a
+// whole object without its alias.
+func (self Query) selectExpressionValue(
+    selectExp qtypes.SelectExpression,
+) (string, []interface{}, error) {
+    if expression, ok := selectExp.Expression(); ok {
+        value, args := expression.QueryValue(self.Tables)
+        return value, args, nil
+    }
+
+    value := selectExp.Column()
+    if tableName, ok := selectExp.Table(); ok {
+        alias, ok := self.Tables.AliasForTable(tableName)
+        if ok {
+            value = fmt.Sprintf("%s.%s", alias, value)
+        } else {
+            value = fmt.Sprintf("%s.%s", tableName, value)
+        }
+    }
+
+    return value, nil, nil
+}
+
+// selectExpressionAlias determines the alias for the table a select
+// expression selects from preferring the specific object it was created from.
+func (self Query) selectExpressionAlias(
+    selectExp qtypes.SelectExpression,
+) (string, error) {
+    if object, ok := selectExp.Object(); ok {
+        alias, err := self.Tables.ObjectAlias(object)
+        if err != nil {
+            return "", errors.Wrap(err, "Selected object is not in query")
+        }
+        return alias, nil
+    }
+
+    tableName, _ := selectExp.Table()
+    if alias, ok := self.Tables.AliasForTable(tableName); ok {
+        return alias, nil
+    }
+    if typ := self.Tables.TypeForAlias(tableName); typ != nil {
+        // The expression was written against an alias directly.
+        return tableName, nil
+    }
+
+    return "", errors.Errorf("Selected table '%s' is not in query", tableName)
+}
+
+func (self Query) buildFrom() (string, []interface{}, error) {
     if self.cached.From.valid {
-        return self.cached.From.query, nil
+        return self.cached.From.query, self.cached.From.values, nil
     }
 
     var fromString string
-    if len(self.joinedObjects) == 1 {
-        // It's just a normal select with no join.
-        onlyObject := self.joinedObjects[0]
-        tableName, err := base.BaseTable(onlyObject)
+    var fromArgs []interface{}
+    relObjects := self.relationshipJoinedObjects()
+    if self.fromSubquery != nil {
+        if len(relObjects) != 0 {
+            return "", nil, errors.New(
+                "Objects must be joined onto a subquery with a join condition",
+            )
+        }
+        fromString, fromArgs = self.fromSubquery.QueryValue(self.Tables)
+        fromString += " " + self.fromSubqueryAlias
+    } else if len(relObjects) == 0 {
+        return "", nil, errors.New(
+            "At least one object must be joined without a join condition",
+        )
+    } else if len(relObjects) == 1 {
+        // It's just a normal select with no relationship join.
+        onlyObject := relObjects[0]
+        objectAlias, err := self.Tables.ObjectAlias(onlyObject)
         if err != nil {
-            return "", errors.Wrap(
+            return "", nil, errors.Wrap(
                 err, "Error while getting object table name",
             )
         }
-        objectAlias, _ := self.Tables.AliasForTable(tableName)
+        tableName := self.Tables.TableForAlias(objectAlias)
         fromString = fmt.Sprintf("%s %s", tableName, objectAlias)
     } else {
-        joinRels := self.solveJoin()
-        if len(joinRels) == 0 {
-            return "", errors.New(
-                "Objects joined don't have relationships with each other",
-            )
+        joinRels, err := self.planJoins()
+        if err != nil {
+            return "", nil, err
         }
-        for _, rel := range joinRels {
-            fromTable, toTable := rel.Tables()
-            fromColumn, toColumn := rel.Columns()
-            fromAlias, _ := self.Tables.AliasForTable(fromTable)
-            toAlias, _ := self.Tables.AliasForTable(toTable)
+        for _, edge := range joinRels {
+            fromAlias, toAlias := edge.fromAlias, edge.toAlias
+            fromTable := self.Tables.TableForAlias(fromAlias)
+            toTable := self.Tables.TableForAlias(toAlias)
+            fromColumn, toColumn := edge.relationship.Columns()
             line := ""
             if len(fromString) == 0 {
                 line += fmt.Sprintf(
@@ -483,7 +933,8 @@ func (self Query) buildFrom() (string, error) {
                 )
             }
             line += fmt.Sprintf(
-                " JOIN %s %s ON %s.%s=%s.%s",
+                " %s %s %s ON %s.%s=%s.%s",
+                self.joinTypes[toAlias],
                 toTable,
                 toAlias,
                 fromAlias,
@@ -495,10 +946,50 @@ func (self Query) buildFrom() (string, error) {
         }
     }
 
+    // Explicitly conditioned joins go last, in the order they were added, so
+    // their conditions can reference any of the other tables.
+    var includedAliases []string
+    for _, alias := range self.Tables.Aliases() {
+        if _, ok := self.joinConditions[alias]; !ok {
+            includedAliases = append(includedAliases, alias)
+        }
+    }
+    for _, object := range self.joinedObjects {
+        alias, err := self.Tables.ObjectAlias(object)
+        if err != nil {
+            return "", nil, err
+        }
+        condition, ok := self.joinConditions[alias]
+        if !ok {
+            continue
+        }
+        switch self.joinTypes[alias] {
+        case qtypes.LeftJoinType, qtypes.FullJoinType:
+            self.Tables.SetAliasNullable(alias, true)
+        }
+        switch self.joinTypes[alias] {
+        case qtypes.RightJoinType, qtypes.FullJoinType:
+            for _, includedAlias := range includedAliases {
+                self.Tables.SetAliasNullable(includedAlias, true)
+            }
+        }
+        includedAliases = append(includedAliases, alias)
+        conditionString, conditionArgs := condition.QueryValue(self.Tables)
+        fromString += fmt.Sprintf(
+            " %s %s %s ON %s",
+            self.joinTypes[alias],
+            self.Tables.TableForAlias(alias),
+            alias,
+            conditionString,
+        )
+        fromArgs = append(fromArgs, conditionArgs...)
+    }
+
     self.cached.From.query = fromString
+    self.cached.From.values = fromArgs
     self.cached.From.valid = true
 
-    return fromString, nil
+    return fromString, fromArgs, nil
 }
 
 func (self Query) buildWhere() (string, []interface{}) {
@@ -526,25 +1017,7 @@ func (self Query) buildOptions() (string, []interface{}) {
         return self.cached.Options.query, self.cached.Options.values
     }
 
-    var (
-        qStrings []string
-        args []interface{}
-    )
-    if len(self.OptionClauses) != 0 {
-        sort.SliceStable(self.OptionClauses, func(i int, j int) bool {
-            optTypeI := self.OptionClauses[i].OptionType()
-            optTypeJ := self.OptionClauses[j].OptionType()
-            return optTypeI < optTypeJ
-        })
-
-        for _, option := range(self.OptionClauses) {
-            qString, qVal := option.QueryValue(self.Tables)
-            qStrings = append(qStrings, qString)
-            args = append(args, qVal...)
-        }
-    }
-
-    optionQuery := strings.Join(qStrings, " ")
+    optionQuery, args := self.optionsQuery(self.OptionClauses)
 
     self.cached.Options.query = optionQuery
     self.cached.Options.values = args
@@ -553,47 +1026,136 @@ func (self Query) buildOptions() (string, []interface{}) {
     return optionQuery, args
 }
 
+// buildOptionsOfType builds only the options of the provided types.
+func (self Query) buildOptionsOfType(
+    optionTypes ...qtypes.OptionType,
+) (string, []interface{}) {
+    var options []qtypes.QueryOption
+    for _, option := range self.OptionClauses {
+        for _, optionType := range optionTypes {
+            if option.OptionType() == optionType {
+                options = append(options, option)
+                break
+            }
+        }
+    }
+
+    return self.optionsQuery(options)
+}
+
+func (self Query) optionsQuery(
+    options []qtypes.QueryOption,
+) (string, []interface{}) {
+    var (
+        qStrings []string
+        args []interface{}
+    )
+    sort.SliceStable(options, func(i int, j int) bool {
+        return options[i].OptionType() < options[j].OptionType()
+    })
+
+    for _, option := range options {
+        qString, qVal := option.QueryValue(self.Tables)
+        qStrings = append(qStrings, qString)
+        args = append(args, qVal...)
+    }
+
+    return strings.Join(qStrings, " "), args
+}
+
 func (self Query) buildQuery() (string, []interface{}, error) {
-    selectString, err := self.buildSelect()
+    if self.keyset != nil {
+        return self.buildKeysetQuery()
+    }
+
+    self.Tables.ClearReportedErrors()
+
+    withString, args, err := self.buildWith()
+    if err != nil {
+        return "", nil, err
+    }
+
+    selectString, selectArgs, err := self.buildSelect()
     if err != nil {
         return "", nil, errors.Wrap(err, "Error while building select clause")
     }
+    args = append(args, selectArgs...)
 
-    fromString, err := self.buildFrom()
+    fromString, fromArgs, err := self.buildFrom()
     if err != nil {
         return "", nil, errors.Wrap(err, "Error while building from clause")
     }
+    args = append(args, fromArgs...)
+
+    // The from clause works out which tables are nullable so the lock is
+    // checked after it's built.
+    if err := self.checkLock(); err != nil {
+        return "", nil, err
+    }
 
     // #nosec G201
     query := fmt.Sprintf(
-        "SELECT %s FROM %s",
+        "%sSELECT %s FROM %s",
+        withString,
         selectString,
         fromString,
     )
 
-    whereQuery, args := self.buildWhere()
+    whereQuery, whereArgs := self.buildWhere()
     if whereQuery != "" {
         query += " " + whereQuery
+        args = append(args, whereArgs...)
+    }
+
+    if len(self.setOperations) != 0 {
+        groupingQuery, groupingArgs := self.buildOptionsOfType(
+            qtypes.GroupByOptionType, qtypes.HavingOptionType,
+        )
+        if groupingQuery != "" {
+            query += " " + groupingQuery
+            args = append(args, groupingArgs...)
+        }
+
+        setQuery, setArgs, err := self.buildSetOperations()
+        if err != nil {
+            return "", nil, err
+        }
+        query += setQuery
+        args = append(args, setArgs...)
+
+        optionQuery, optArgs, err := self.buildCombinedOptions()
+        if err != nil {
+            return "", nil, err
+        }
+        if optionQuery != "" {
+            query += " " + optionQuery
+            args = append(args, optArgs...)
+        }
+    } else {
+        optionQuery, optArgs := self.buildOptions()
+        if optionQuery != "" {
+            query += " " + optionQuery
+            args = append(args, optArgs...)
+        }
     }
 
-    optionQuery, optArgs := self.buildOptions()
-    if optionQuery != "" {
-        query += " " + optionQuery
-        args = append(args, optArgs...)
+    if reported := self.Tables.ReportedErrors(); len(reported) != 0 {
+        return "", nil, reported[0]
     }
 
     return query, args, nil
 }
 
-func (self Query) getSelectableColumns(typ reflect.Type) ([]string, error) {
-    bsFieldMap := self.typeFieldNameBSFieldMap[typ]
-    objTable := self.Tables.TypeTable(typ)
-    objAlias, ok := self.Tables.AliasForTable(objTable)
-    if !ok {
-        objAlias = objTable
+func (self Query) getSelectableColumns(
+    objAlias string,
+) ([]selectColumn, error) {
+    typ := self.Tables.TypeForAlias(objAlias)
+    if typ == nil {
+        return nil, errors.Errorf("Alias '%s' is not in query", objAlias)
     }
+    bsFieldMap := self.typeFieldNameBSFieldMap[*typ]
 
-    var columns []string
+    var columns []selectColumn
     for _, bsField := range *bsFieldMap {
         var foreignAlias string
         var column string
@@ -621,78 +1183,275 @@ func (self Query) getSelectableColumns(typ reflect.Type) ([]string, error) {
                     )
                 }
             }
-            aliasedColumn := fmt.Sprintf(
-                "%s.%s as %s_%s",
-                foreignAlias,
-                column,
-                objAlias,
-                column,
-            )
-            columns = append(columns, aliasedColumn)
+            expression := fmt.Sprintf("%s.%s", foreignAlias, column)
+            valueType := reflect.TypeOf(bsField.Interface())
+            if self.plainColumns {
+                columns = append(columns, selectColumn{
+                    expression: expression,
+                    name: column,
+                    column: column,
+                    valueType: valueType,
+                    sql: expression,
+                })
+                continue
+            }
+            name := fmt.Sprintf("%s_%s", objAlias, column)
+            columns = append(columns, selectColumn{
+                expression: expression,
+                name: name,
+                column: column,
+                valueType: valueType,
+                sql: fmt.Sprintf("%s as %s", expression, name),
+            })
         }
     }
 
+    sort.Slice(columns, func(i, j int) bool {
+        return columns[i].sql < columns[j].sql
+    })
+
     return columns, nil
 }
 
-func (self *Query) solveJoin() []*base.Relationship {
-    results := make([]*base.Relationship, 0)
-    matches := make(map[string][]base.Base)
-    for _, toObject := range self.joinedObjects {
-        toTableName, _ := base.BaseTable(toObject)
-        if _, ok := matches[toTableName]; !ok {
-            matches[toTableName] = make([]base.Base, 0)
-        } else {
-            continue
+// relationshipJoinedObjects retrieves the joined objects which are joined via
+// their relationships rather than an explicit condition.
+func (self Query) relationshipJoinedObjects() []base.Base {
+    objects := make([]base.Base, 0, len(self.joinedObjects))
+    for _, object := range self.joinedObjects {
+        alias, err := self.Tables.ObjectAlias(object)
+        if err == nil {
+            if _, ok := self.joinConditions[alias]; ok {
+                continue
+            }
         }
-    ToLoop:
-        for _, fromObject := range self.joinedObjects {
-            fromTableName, _ := base.BaseTable(fromObject)
-            if _, ok := matches[fromTableName]; !ok {
-                matches[fromTableName] = make([]base.Base, 0)
+        objects = append(objects, object)
+    }
+
+    return objects
+}
+
+// intermediateAliasFunc retrieves the alias of the table joined to connect
+// the object with the provided alias to the rest of the query.
+type intermediateAliasFunc func(
+    fromAlias string, relationship *base.Relationship,
+) (string, error)
+
+// solveJoin finds a relationship connecting each joined object to one of the
+// objects joined before it. The tables in between objects which are only
+// connected through link models are looked up with the provided function.
+func (self Query) solveJoin(
+    intermediateAlias intermediateAliasFunc,
+) ([]*joinEdge, error) {
+    results := make([]*joinEdge, 0)
+    relObjects := self.relationshipJoinedObjects()
+    if len(relObjects) == 0 {
+        return results, nil
+    }
+
+    included := []base.Base{relObjects[0]}
+    remaining := relObjects[1:]
+    for len(remaining) > 0 {
+        var unmatched []base.Base
+        for _, fromObject := range remaining {
+            fromAlias, err := self.Tables.ObjectAlias(fromObject)
+            if err != nil {
+                return nil, err
             }
+            name := self.joinVia[fromAlias]
 
-            if objectMatches, ok := matches[fromTableName]; ok {
-                for _, match := range objectMatches {
-                    if match == toObject {
-                        goto ToLoop
-                    }
+            var edge *joinEdge
+            for _, toObject := range included {
+                joinRel, err := findRelationshipBetweenObjects(
+                    fromObject, toObject, name,
+                )
+                if errors.Cause(err) == AmbiguousRelationshipError {
+                    return nil, err
+                } else if err != nil {
+                    continue
                 }
-            }
-            if objectMatches, ok := matches[toTableName]; ok {
-                for _, match := range objectMatches {
-                    if match == fromObject {
-                        goto ToLoop
-                    }
+
+                toAlias, err := self.Tables.ObjectAlias(toObject)
+                if err != nil {
+                    return nil, err
+                }
+                edge = &joinEdge{
+                    relationship: joinRel,
+                    fromAlias: fromAlias,
+                    toAlias: toAlias,
                 }
+                break
             }
-            if fromObject == toObject {
+
+            if edge == nil {
+                unmatched = append(unmatched, fromObject)
                 continue
             }
+            results = append(results, edge)
+            included = append(included, fromObject)
+        }
 
-            joinRel, err := findRelationshipBetweenObjects(
-                fromObject, toObject,
+        if len(unmatched) == len(remaining) {
+            // Nothing joins directly so look for a path through tables that
+            // weren't explicitly joined.
+            pathEdges, pathObject, err := self.solveJoinPath(
+                unmatched, included, intermediateAlias,
             )
-            if err == nil {
-                results = append(results, joinRel)
-                fromTable, toTable := joinRel.Tables()
-                matches[fromTable] = append(matches[fromTable], toObject)
-                matches[toTable] = append(matches[toTable], fromObject)
+            if err != nil {
+                return nil, err
+            }
+            if pathObject == nil {
                 break
             }
+            results = append(results, pathEdges...)
+            included = append(included, pathObject)
+
+            remaining = make([]base.Base, 0, len(unmatched) - 1)
+            for _, object := range unmatched {
+                if object != pathObject {
+                    remaining = append(remaining, object)
+                }
+            }
+            continue
         }
+        remaining = unmatched
     }
 
-    return results
+    return results, nil
+}
+
+// solveJoinPath finds the shortest path of relationships connecting one of
+// the remaining objects to the included objects.
+func (self Query) solveJoinPath(
+    remaining, included []base.Base, intermediateAlias intermediateAliasFunc,
+) ([]*joinEdge, base.Base, error) {
+    var objects []base.Base
+    for _, object := range self.relationshipJoinedObjects() {
+        objects = append(objects, qtypes.UnaliasedObject(object))
+    }
+    graph := newRelationshipGraph(objects)
+
+    targetAliases := make(map[string]string, len(included))
+    targetTables := make(map[string]bool, len(included))
+    for _, object := range included {
+        alias, err := self.Tables.ObjectAlias(object)
+        if err != nil {
+            return nil, nil, err
+        }
+        table := self.Tables.TableForAlias(alias)
+        if _, ok := targetAliases[table]; !ok {
+            targetAliases[table] = alias
+        }
+        targetTables[table] = true
+    }
+
+    for _, fromObject := range remaining {
+        fromAlias, err := self.Tables.ObjectAlias(fromObject)
+        if err != nil {
+            return nil, nil, err
+        }
+        path, err := graph.shortestPath(
+            self.Tables.TableForAlias(fromAlias), targetTables,
+        )
+        if errors.Cause(err) == AmbiguousRelationshipError {
+            return nil, nil, err
+        } else if err != nil {
+            continue
+        }
+
+        edges := make([]*joinEdge, len(path))
+        currentAlias := fromAlias
+        for i, relationship := range path {
+            var toAlias string
+            if i == len(path) - 1 {
+                _, targetTable := relationship.Tables()
+                toAlias = targetAliases[targetTable]
+            } else {
+                toAlias, err = intermediateAlias(fromAlias, relationship)
+                if err != nil {
+                    return nil, nil, err
+                }
+            }
+            edges[i] = &joinEdge{
+                relationship: relationship,
+                fromAlias: currentAlias,
+                toAlias: toAlias,
+            }
+            currentAlias = toAlias
+        }
+
+        return edges, fromObject, nil
+    }
+
+    return nil, nil, nil
+}
+
+// addIntermediateTables adds any tables needed to connect the joined objects
+// through link models. They're added as objects are joined so that building
+// the query doesn't change it. Errors are left to be reported when the query
+// is built since joining more objects may still resolve them.
+func (self *Query) addIntermediateTables() {
+    _, _ = self.solveJoin(self.addIntermediateAlias)
+}
+
+// joinedIntermediateAlias retrieves the alias of a table which was added to
+// connect the object with the provided alias to the rest of the query.
+func (self Query) joinedIntermediateAlias(
+    fromAlias string, relationship *base.Relationship,
+) (string, error) {
+    _, targetTable := relationship.Tables()
+    key := fromAlias + ":" + targetTable
+    if alias, ok := self.intermediateAliases[key]; ok {
+        return alias, nil
+    }
+
+    return "", errors.Errorf(
+        "Table '%s' connecting '%s' to the query wasn't joined, link " +
+            "models must be registered before objects are joined",
+        targetTable,
+        fromAlias,
+    )
+}
+
+// addIntermediateAlias retrieves (or creates) the alias for a table which was
+// added to connect the object with the provided alias to the rest of the
+// query. The table is added to the query but isn't selected by default.
+func (self *Query) addIntermediateAlias(
+    fromAlias string, relationship *base.Relationship,
+) (string, error) {
+    _, targetTable := relationship.Tables()
+    key := fromAlias + ":" + targetTable
+    if alias, ok := self.intermediateAliases[key]; ok {
+        return alias, nil
+    }
+
+    _, targetType := relationship.Types()
+    object := reflect.New(targetType).Interface()
+    err := self.Tables.AddObjects(object)
+    if err != nil {
+        return "", errors.Wrap(
+            err, "Error while adding intermediate object to join",
+        )
+    }
+    alias, err := self.Tables.ObjectAlias(object)
+    if err != nil {
+        return "", err
+    }
+
+    self.Tables.SetAliasHidden(alias, true)
+    self.joinTypes[alias] = self.joinTypes[fromAlias]
+    self.intermediateAliases[key] = alias
+
+    return alias, nil
 }
 
 // TODO: Audit performance. Consider short-circut conditions.
-func findRelationshipBetweenObjects(object1, object2 base.Base) (
-    *base.Relationship,
-    error,
-) {
+func findRelationshipBetweenObjects(
+    object1, object2 base.Base, name string,
+) (*base.Relationship, error) {
+    object1 = qtypes.UnaliasedObject(object1)
+    object2 = qtypes.UnaliasedObject(object2)
     isRelationshipable := false
-    matches := make(map[base.Base][]base.Relationship)
+    var candidates []*base.Relationship
 
     obj1Table, _ := base.BaseTable(object1)
     obj2Table, _ := base.BaseTable(object2)
@@ -704,41 +1463,63 @@ func findRelationshipBetweenObjects(object1, object2 base.Base) (
             // TODO: Consider using reflected name to check for names as well.
             _, targetTable := relationship.Tables()
             if targetTable == obj2Table {
-                if matchRels, ok := matches[object1]; ok {
-                    matches[object1] = append(matchRels, relationship)
-                } else {
-                    matchRels := []base.Relationship{relationship}
-                    matches[object1] = matchRels
-                }
+                joinRel := relationship
+                candidates = append(candidates, &joinRel)
             }
         }
     }
     if relationshipable, ok := object2.(base.Relationshipable); ok {
+        isRelationshipable = true
+
         for _, relationship := range relationshipable.Relationships() {
             // TODO: Consider using reflected name to check for names as well.
             _, targetTable := relationship.Tables()
             if targetTable == obj1Table {
-                if matchRels, ok := matches[object2]; ok {
-                    matches[object2] = append(matchRels, relationship)
-                } else {
-                    matchRels := []base.Relationship{relationship}
-                    matches[object2] = matchRels
-                }
+                candidates = append(candidates, relationship.Invert())
             }
         }
     }
 
-    if joinRels, ok := matches[object1]; ok {
-        joinRel := joinRels[0]
-        return &joinRel, nil
-    } else if joinRels, ok := matches[object2]; ok {
-        joinRel := joinRels[0]
+    if !isRelationshipable {
+        return nil, errors.New("None of the objects have relationships")
+    }
 
-        return joinRel.Invert(), nil
+    // Both objects may declare the same relationship from either side so
+    // only distinct column pairings count as separate candidates.
+    var matches []*base.Relationship
+    seenColumns := make(map[[2]string]bool)
+    for _, candidate := range candidates {
+        if name != "" && candidate.Name() != name {
+            continue
+        }
+        selfColumn, targetColumn := candidate.Columns()
+        columns := [2]string{selfColumn, targetColumn}
+        if seenColumns[columns] {
+            continue
+        }
+        seenColumns[columns] = true
+        matches = append(matches, candidate)
     }
 
-    if !isRelationshipable {
-        return nil, errors.New("None of the objects have relationships")
+    if len(matches) == 1 {
+        return matches[0], nil
+    } else if len(matches) > 1 {
+        return nil, errors.Wrapf(
+            AmbiguousRelationshipError,
+            "Found %d relationships between '%s' and '%s'",
+            len(matches),
+            obj1Table,
+            obj2Table,
+        )
+    }
+
+    if name != "" {
+        return nil, errors.Errorf(
+            "No relationship named '%s' between '%s' and '%s'",
+            name,
+            obj1Table,
+            obj2Table,
+        )
     }
 
     return nil, errors.New(
@@ -759,6 +1540,10 @@ func NewQuery(pool *pool.ConnectionPool) *Query {
             map[reflect.Type]*refl.GroupedFieldsWithBS,
         ),
         joinedObjects: make([]base.Base, 0),
+        joinTypes: make(map[string]qtypes.JoinType),
+        joinConditions: make(map[string]qtypes.Queryable),
+        joinVia: make(map[string]string),
+        intermediateAliases: make(map[string]string),
 
         cached: cachedQuery{
             Select: queryValuePair{},
@@ -0,0 +1,382 @@
+package query
+
+import (
+    "database/sql"
+    "reflect"
+
+    "github.com/jmoiron/sqlx"
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/base"
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// InTx runs the query in the provided transaction instead of a new one. The
+// transaction is left open for the caller to commit or roll back, so any
+// rows locked by the query stay locked until then.
+func (self *Query) InTx(tx *sqlx.Tx) *Query {
+    if tx == nil {
+        self.Errors = append(self.Errors, errors.New(
+            "Can't run a query in a nil transaction",
+        ))
+        return self
+    }
+    self.tx = tx
+
+    return self
+}
+
+// runQuery runs the provided query in a new transaction. The transaction is
+// rolled back if the query fails. When the query was given a transaction with
+// InTx it's run there instead and no transaction is returned since finishing
+// it is left to the caller.
+func (self Query) runQuery(
+    query string, args []interface{},
+) (*sqlx.Tx, *sqlx.Rows, error) {
+    if self.tx != nil {
+        rows, err := self.queryInTx(self.tx, query, args)
+        return nil, rows, err
+    }
+    if self.locksRows() {
+        return nil, nil, errors.New(
+            "Locking rows requires running the query in a transaction " +
+                "with InTx",
+        )
+    }
+
+    tx, err := self.Pool.Beginx()
+    if err != nil {
+        return nil, nil, err
+    }
+
+    rows, err := self.queryInTx(tx, query, args)
+    if err != nil {
+        newErr := tx.Rollback()
+        if newErr != nil {
+            return nil, nil, newErr
+        }
+
+        return nil, nil, err
+    }
+
+    return tx, rows, nil
+}
+
+// queryInTx runs the provided query in an existing transaction.
+func (self Query) queryInTx(
+    tx *sqlx.Tx, query string, args []interface{},
+) (*sqlx.Rows, error) {
+    // NOTE: Every argument is named so NamedQuery binds them in the style
+    //       of the driver; rebinding here would also rewrite any literal `?`
+    //       in the query (such as the Postgres jsonb operators).
+    variableMap := make(map[string]interface{}, len(args))
+    for _, variable := range args {
+        if namedVar, ok := variable.(sql.NamedArg); ok {
+            variableMap[namedVar.Name] = namedVar.Value
+        }
+    }
+
+    return tx.NamedQuery(query, variableMap)
+}
+
+// finishQuery closes the provided rows and commits the transaction or rolls
+// it back if an error occurred. A nil transaction belongs to the caller and is
+// left open.
+func finishQuery(tx *sqlx.Tx, rows *sqlx.Rows, err error) error {
+    rows.Close()
+    if tx == nil {
+        return err
+    }
+    if err != nil {
+        newErr := tx.Rollback()
+        if newErr != nil {
+            return errors.Wrapf(
+                newErr,
+                "Error rolling back transaction in response to '%s'",
+                err.Error(),
+            )
+        }
+        return err
+    }
+
+    err = tx.Commit()
+    if err != nil {
+        newErr := tx.Rollback()
+        if newErr != nil {
+            return newErr
+        }
+    }
+
+    return err
+}
+
+// runBuiltQuery builds and runs the query.
+func (self Query) runBuiltQuery() (*sqlx.Tx, *sqlx.Rows, error) {
+    if len(self.Errors) != 0 {
+        return nil, nil, errors.Errorf(
+            "Errors while forming query:\n%#+v",
+            self.Errors,
+        )
+    }
+
+    query, args, err := self.buildQuery()
+    if err != nil {
+        return nil, nil, errors.Wrap(err, "Error while building query")
+    }
+
+    return self.runQuery(query, args)
+}
+
+// Scalar runs the query and reads the single value it selects (such as an
+// aggregate) from the first row into dest which should be a pointer.
+func (self Query) Scalar(dest interface{}) (err error) {
+    tx, rows, err := self.runBuiltQuery()
+    if err != nil {
+        return err
+    }
+    defer func() {
+        err = finishQuery(tx, rows, err)
+    }()
+
+    columns, err := rows.Columns()
+    if err != nil {
+        return err
+    }
+    if len(columns) != 1 {
+        return errors.Errorf(
+            "Scalar requires exactly one selected column not %d",
+            len(columns),
+        )
+    }
+
+    if !rows.Next() {
+        if err := rows.Err(); err != nil {
+            return err
+        }
+        return sql.ErrNoRows
+    }
+
+    return rows.Scan(dest)
+}
+
+// Aggregate runs the query and reads the results into dest without using the
+// alias_column naming scheme. dest may be a pointer to a struct, in which case
+// the first row is read, or a pointer to a slice of structs (or struct
+// pointers) in which case every row is read. Columns are matched to fields by
+// their `db` tag or field name.
+func (self Query) Aggregate(dest interface{}) (err error) {
+    destVal := reflect.ValueOf(dest)
+    if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+        return errors.Errorf(
+            "Aggregate requires a pointer to a struct or slice not %T", dest,
+        )
+    }
+    destVal = destVal.Elem()
+
+    var structType reflect.Type
+    isSlice := destVal.Kind() == reflect.Slice
+    if isSlice {
+        structType = destVal.Type().Elem()
+        if structType.Kind() == reflect.Ptr {
+            structType = structType.Elem()
+        }
+    } else {
+        structType = destVal.Type()
+    }
+    if structType.Kind() != reflect.Struct {
+        return errors.Errorf(
+            "Aggregate requires a pointer to a struct or slice not %T", dest,
+        )
+    }
+
+    tx, rows, err := self.runBuiltQuery()
+    if err != nil {
+        return err
+    }
+    defer func() {
+        err = finishQuery(tx, rows, err)
+    }()
+
+    columns, err := rows.Columns()
+    if err != nil {
+        return err
+    }
+    scanner, err := qtypes.NewStructScanner(structType, columns)
+    if err != nil {
+        return err
+    }
+
+    if !isSlice {
+        if !rows.Next() {
+            if err := rows.Err(); err != nil {
+                return err
+            }
+            return sql.ErrNoRows
+        }
+        return scanner.Scan(rows, destVal)
+    }
+
+    elemIsPtr := destVal.Type().Elem().Kind() == reflect.Ptr
+    for rows.Next() {
+        elemPtr := reflect.New(structType)
+        err := scanner.Scan(rows, elemPtr.Elem())
+        if err != nil {
+            return err
+        }
+        elem := elemPtr
+        if !elemIsPtr {
+            elem = elemPtr.Elem()
+        }
+        destVal.Set(reflect.Append(destVal, elem))
+    }
+
+    return rows.Err()
+}
+
+// readSingle reads the first result of the query, limited to the provided
+// number of rows, into the provided objects. It reports whether there were
+// more results.
+func (self Query) readSingle(
+    limit int, objects []base.Base,
+) (more bool, err error) {
+    limited := self
+    limited.OptionClauses = append(
+        []qtypes.QueryOption(nil), self.OptionClauses...,
+    )
+    limited.Limit(limit)
+
+    results, err := limited.Results()
+    if err != nil {
+        return false, err
+    }
+    defer func() {
+        closeErr := results.Close()
+        if err == nil {
+            err = closeErr
+        }
+    }()
+
+    if !results.Next() {
+        if err := results.Err(); err != nil {
+            return false, err
+        }
+        return false, NotFoundError
+    }
+    err = results.GetResult().WriteTo(objects...)
+    if err != nil {
+        return false, err
+    }
+
+    more = results.Next()
+
+    return more, results.Err()
+}
+
+// First writes the first result of the query into the provided objects in
+// the same fashion as QueryResult.WriteTo. NotFoundError is returned if there
+// are no results.
+func (self Query) First(objects ...base.Base) error {
+    _, err := self.readSingle(1, objects)
+    return err
+}
+
+// One writes the only result of the query into the provided objects in the
+// same fashion as QueryResult.WriteTo. NotFoundError is returned if there are
+// no results and MultipleResultsError if there's more than one.
+func (self Query) One(objects ...base.Base) error {
+    more, err := self.readSingle(2, objects)
+    if err != nil {
+        return err
+    }
+    if more {
+        return MultipleResultsError
+    }
+
+    return nil
+}
+
+// Exists checks if the query has any results without reading them.
+func (self Query) Exists() (exists bool, err error) {
+    if len(self.Errors) != 0 {
+        return false, errors.Errorf(
+            "Errors while forming query:\n%#+v",
+            self.Errors,
+        )
+    }
+
+    query, args, err := self.buildQuery()
+    if err != nil {
+        return false, errors.Wrap(err, "Error while building query")
+    }
+
+    // #nosec G201
+    tx, rows, err := self.runQuery("SELECT EXISTS(" + query + ")", args)
+    if err != nil {
+        return false, err
+    }
+    defer func() {
+        err = finishQuery(tx, rows, err)
+    }()
+
+    if rows.Next() {
+        err = rows.Scan(&exists)
+        if err != nil {
+            return false, err
+        }
+    }
+
+    return exists, rows.Err()
+}
+
+// Pluck runs the query selecting only the provided column and appends its
+// value from each row to dest which should be a pointer to a slice of a type
+// the column can be scanned into (such as `*[]int64`). Use pointer or
+// sql.Null* elements for columns that can be NULL. Rows are scanned directly
+// without being written to any objects. Queries combined with set operations
+// can't be plucked since the column would only be selected by this query.
+func (self Query) Pluck(
+    column qtypes.Queryable, dest interface{},
+) (err error) {
+    if column == nil {
+        return errors.New("Can't pluck a nil column")
+    }
+    destVal := reflect.ValueOf(dest)
+    if destVal.Kind() != reflect.Ptr || destVal.IsNil() ||
+        destVal.Elem().Kind() != reflect.Slice {
+        return errors.Errorf(
+            "Pluck requires a pointer to a slice not %T", dest,
+        )
+    }
+    if len(self.setOperations) != 0 {
+        return errors.New(
+            "Can't pluck a column from a query with set operations",
+        )
+    }
+    sliceVal := destVal.Elem()
+    elemType := sliceVal.Type().Elem()
+
+    plucked := self
+    plucked.SelectExpressions = []qtypes.SelectExpression{
+        qtypes.NewQueryableSelectExpression(column, ""),
+    }
+    plucked.cached.Select.invalidate()
+
+    tx, rows, err := plucked.runBuiltQuery()
+    if err != nil {
+        return err
+    }
+    defer func() {
+        err = finishQuery(tx, rows, err)
+    }()
+
+    for rows.Next() {
+        elemPtr := reflect.New(elemType)
+        err := rows.Scan(elemPtr.Interface())
+        if err != nil {
+            return errors.Wrap(err, "Error while reading plucked column")
+        }
+        sliceVal.Set(reflect.Append(sliceVal, elemPtr.Elem()))
+    }
+
+    return rows.Err()
+}
@@ -9,9 +9,11 @@ import (
     . "github.com/onsi/gomega"
     "github.com/DATA-DOG/go-sqlmock"
     "github.com/jmoiron/sqlx"
+    "github.com/pkg/errors"
 
     "github.com/daihasso/machgo/pool"
     "github.com/daihasso/machgo/pool/dbtype"
+    "github.com/daihasso/machgo/query/dot"
     qt "github.com/daihasso/machgo/query/qtypes"
     "github.com/daihasso/machgo/base"
 )
@@ -32,6 +34,105 @@ func (self *secondTestObject) Relationships() []base.Relationship {
     }
 }
 
+type thirdTestObject struct {
+    Id int64 `db:"id"`
+    TestObjectId int64 `db:"test_object_id"`
+    Active bool `db:"active"`
+}
+
+type treeTestObject struct {
+    Id int64 `db:"id"`
+    ParentId *int64 `db:"parent_id"`
+}
+
+type userTestObject struct {
+    Id int64 `db:"id"`
+}
+
+type postTestObject struct {
+    Id int64 `db:"id"`
+    CreatedBy int64 `db:"created_by"`
+    UpdatedBy int64 `db:"updated_by"`
+}
+
+func (self *postTestObject) Relationships() []base.Relationship {
+    return []base.Relationship{
+        base.MustNamedRelationship(
+            "author", self, "created_by", &userTestObject{}, "id",
+        ),
+        base.MustNamedRelationship(
+            "editor", self, "updated_by", &userTestObject{}, "id",
+        ),
+    }
+}
+
+type imageTestObject struct {
+    Id int64 `db:"id"`
+}
+
+type postImageTestObject struct {
+    PostId int64 `db:"post_id"`
+    ImageId int64 `db:"image_id"`
+}
+
+func (self *postImageTestObject) Relationships() []base.Relationship {
+    return []base.Relationship{
+        base.MustRelationship(self, "post_id", &postTestObject{}, "id"),
+        base.MustRelationship(self, "image_id", &imageTestObject{}, "id"),
+    }
+}
+
+type aaLinkTestObject struct {
+    PostId int64 `db:"post_id"`
+    ImageId int64 `db:"image_id"`
+}
+
+func (self *aaLinkTestObject) Relationships() []base.Relationship {
+    return []base.Relationship{
+        base.MustRelationship(self, "post_id", &postTestObject{}, "id"),
+        base.MustRelationship(self, "image_id", &imageTestObject{}, "id"),
+    }
+}
+
+type postAuthorTestObject struct {
+    PostId int64 `db:"post_id"`
+    UserId int64 `db:"user_id"`
+}
+
+func (self *postAuthorTestObject) Relationships() []base.Relationship {
+    return []base.Relationship{
+        base.MustRelationship(self, "post_id", &postTestObject{}, "id"),
+        base.MustRelationship(self, "user_id", &userTestObject{}, "id"),
+    }
+}
+
+type tagTestObject struct {
+    Id int64 `db:"id"`
+}
+
+type imageTagTestObject struct {
+    ImageId int64 `db:"image_id"`
+    TagId int64 `db:"tag_id"`
+}
+
+func (self *imageTagTestObject) Relationships() []base.Relationship {
+    return []base.Relationship{
+        base.MustRelationship(self, "image_id", &imageTestObject{}, "id"),
+        base.MustRelationship(self, "tag_id", &tagTestObject{}, "id"),
+    }
+}
+
+type featuredTagTestObject struct {
+    ImageId int64 `db:"image_id"`
+    TagId int64 `db:"tag_id"`
+}
+
+func (self *featuredTagTestObject) Relationships() []base.Relationship {
+    return []base.Relationship{
+        base.MustRelationship(self, "image_id", &imageTestObject{}, "id"),
+        base.MustRelationship(self, "tag_id", &tagTestObject{}, "id"),
+    }
+}
 
 var _ = Describe("Query", func() {
     var (
@@ -179,5 +280,1172 @@ var _ = Describe("Query", func() {
             fmt.Fprint(GinkgoWriter, queryString)
             Expect(queryString).To(Equal(expectedQuery))
         })
+        It("should be able to left join an object", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ` +
+                `LEFT JOIN second_test_objects b ON a.id=b.id ` +
+                `WHERE (a.id = :const_4639577150595001395)', ` +
+                `args: (const_4639577150595001395: 55)`
+
+            object := &testObject{}
+            object2 := &secondTestObject{}
+
+            objColumn, err := qt.ObjectColumn(object, "id")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(object).LeftJoin(object2).Where(qt.NewDefaultCondition(
+                objColumn,
+                qt.InterfaceToQueryable(55),
+                qt.EqualCombiner,
+            )).Select(qt.BaseSelectable(object))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+            Expect(q.Tables.AliasNullable("b")).To(BeTrue())
+            Expect(q.Tables.AliasNullable("a")).To(BeFalse())
+        })
+        It("should be able to join the same table with an alias", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, ` +
+                `a.parent_id as a_parent_id, parent.id as parent_id, ` +
+                `parent.parent_id as parent_parent_id ` +
+                `FROM tree_test_objects a ` +
+                `JOIN tree_test_objects parent ON (a.parent_id = parent.id) ` +
+                `WHERE (parent.id = :const_4639577150595001395)', ` +
+                `args: (const_4639577150595001395: 55)`
+
+            child := &treeTestObject{}
+            parent := As(&treeTestObject{}, "parent")
+
+            parentIdColumn, err := qt.ObjectColumn(child, "parent_id")
+            Expect(err).ToNot(HaveOccurred())
+            idColumn, err := qt.ObjectColumn(parent, "id")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(child).JoinOn(parent, qt.NewDefaultCondition(
+                parentIdColumn, idColumn, qt.EqualCombiner,
+            )).Where(qt.NewDefaultCondition(
+                qt.AliasColumn("parent", "id"),
+                qt.InterfaceToQueryable(55),
+                qt.EqualCombiner,
+            ))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should read results for a mixed case alias", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.parent_id as a_parent_id, ` +
+                    `parent.id as parent_id, ` +
+                    `parent.parent_id as parent_parent_id ` +
+                    `FROM tree_test_objects a ` +
+                    `JOIN tree_test_objects parent ` +
+                    `ON \(a.parent_id = parent.id\)$`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{
+                    "a_id", "a_parent_id", "parent_id", "parent_parent_id",
+                }).AddRow(2, 1, 1, nil),
+            )
+            mock.ExpectCommit()
+
+            child := &treeTestObject{}
+            parent := As(&treeTestObject{}, "Parent")
+
+            parentIdColumn, err := qt.ObjectColumn(child, "parent_id")
+            Expect(err).ToNot(HaveOccurred())
+            idColumn, err := qt.ObjectColumn(parent, "id")
+            Expect(err).ToNot(HaveOccurred())
+            results, err := q.Join(child).JoinOn(
+                parent,
+                qt.NewDefaultCondition(
+                    parentIdColumn, idColumn, qt.EqualCombiner,
+                ),
+            ).Results()
+            Expect(err).ToNot(HaveOccurred())
+
+            var children, parents []*treeTestObject
+            err = results.WriteAllTo(&children, As(&parents, "Parent"))
+            Expect(err).ToNot(HaveOccurred())
+            parentId := int64(1)
+            Expect(children).To(Equal([]*treeTestObject{
+                {Id: 2, ParentId: &parentId},
+            }))
+            Expect(parents).To(Equal([]*treeTestObject{{Id: 1}}))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should select a specific aliased object", func() {
+            expectedQuery := `query: 'SELECT parent.id as parent_id, ` +
+                `parent.parent_id as parent_parent_id ` +
+                `FROM tree_test_objects a ` +
+                `JOIN tree_test_objects parent ` +
+                `ON (a.parent_id = parent.id)', ` +
+                `args: ()`
+
+            child := &treeTestObject{}
+            parent := As(&treeTestObject{}, "parent")
+
+            parentIdColumn, err := qt.ObjectColumn(child, "parent_id")
+            Expect(err).ToNot(HaveOccurred())
+            idColumn, err := qt.ObjectColumn(parent, "id")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(child).JoinOn(parent, qt.NewDefaultCondition(
+                parentIdColumn, idColumn, qt.EqualCombiner,
+            )).Select(qt.BaseSelectable(parent))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should refuse ambiguous relationships", func() {
+            post := &postTestObject{}
+            user := &userTestObject{}
+            q.Join(post, user).Select(qt.BaseSelectable(post))
+
+            _, _, err := q.buildQuery()
+            Expect(err).To(HaveOccurred())
+            Expect(errors.Cause(err)).To(Equal(AmbiguousRelationshipError))
+        })
+        It("should join via a named relationship", func() {
+            expectedQuery := `query: 'SELECT a.created_by as a_created_by, ` +
+                `a.id as a_id, a.updated_by as a_updated_by ` +
+                `FROM user_test_objects author ` +
+                `JOIN post_test_objects a ON author.id=a.created_by ` +
+                `JOIN user_test_objects editor ON a.updated_by=editor.id', ` +
+                `args: ()`
+
+            post := &postTestObject{}
+            q.Join(post).JoinVia(
+                "author", As(&userTestObject{}, "author"),
+            ).JoinVia(
+                "editor", As(&userTestObject{}, "editor"),
+            ).Select(qt.BaseSelectable(post))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should join through intermediate tables", func() {
+            expectedQuery := `query: 'SELECT a.created_by as a_created_by, ` +
+                `a.id as a_id, a.updated_by as a_updated_by, b.id as b_id ` +
+                `FROM image_test_objects b ` +
+                `JOIN post_image_test_objects c ON b.id=c.image_id ` +
+                `JOIN post_test_objects a ON c.post_id=a.id', ` +
+                `args: ()`
+
+            err := base.RegisterLinkModels(&postImageTestObject{})
+            Expect(err).ToNot(HaveOccurred())
+
+            q.Join(&postTestObject{}, &imageTestObject{})
+            Expect(q.Tables.Aliases()).To(ConsistOf("a", "b", "c"))
+
+            // Counted and printed twice to make sure building the query
+            // doesn't add the intermediate table again and that it isn't
+            // selected.
+            _, _, err = q.buildCountQuery()
+            Expect(err).ToNot(HaveOccurred())
+            q.PrintQuery()
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+            Expect(q.Tables.Aliases()).To(ConsistOf("a", "b", "c"))
+        })
+        It("should only join through registered link models", func() {
+            expectedQuery := `query: 'SELECT a.created_by as a_created_by, ` +
+                `a.id as a_id, a.updated_by as a_updated_by, b.id as b_id ` +
+                `FROM image_test_objects b ` +
+                `JOIN post_image_test_objects c ON b.id=c.image_id ` +
+                `JOIN post_test_objects a ON c.post_id=a.id', ` +
+                `args: ()`
+
+            // Describing a model doesn't make it a link model.
+            _, err := base.BaseTable(&aaLinkTestObject{})
+            Expect(err).ToNot(HaveOccurred())
+            err = base.RegisterLinkModels(
+                &postImageTestObject{}, &postAuthorTestObject{},
+            )
+            Expect(err).ToNot(HaveOccurred())
+
+            q.Join(&postTestObject{}, &imageTestObject{})
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should refuse equally short join paths", func() {
+            err := base.RegisterLinkModels(
+                &imageTagTestObject{}, &featuredTagTestObject{},
+            )
+            Expect(err).ToNot(HaveOccurred())
+
+            q.Join(&imageTestObject{}, &tagTestObject{})
+
+            _, _, err = q.buildQuery()
+            Expect(errors.Cause(err)).To(Equal(AmbiguousRelationshipError))
+        })
+        It("should be able to group and filter groups", func() {
+            expectedQuery := `query: 'SELECT a.name, COUNT(a.id) AS total ` +
+                `FROM test_objects a ` +
+                `GROUP BY a.name ` +
+                `HAVING (COUNT(a.id) > :const_4639577150595001395) ` +
+                `ORDER BY a.name LIMIT 5', ` +
+                `args: (const_4639577150595001395: 1)`
+
+            object := &testObject{}
+            nameColumn, err := qt.ObjectColumn(object, "name")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(object).Select(
+                qt.LiteralSelectable("test_objects.name"),
+                qt.LiteralSelectable("COUNT(a.id) AS total"),
+            ).Limit(5).OrderBy(nameColumn).Having(qt.NewDefaultCondition(
+                qt.LiteralQueryable{Value: "COUNT(a.id)"},
+                qt.InterfaceToQueryable(1),
+                qt.GreaterThanCombiner,
+            )).GroupBy(nameColumn)
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to select aggregates", func() {
+            expectedQuery := `query: 'SELECT a.name, ` +
+                `COUNT(DISTINCT a.id) AS ids, SUM(a.id) AS total, ` +
+                `MAX(a.id) ` +
+                `FROM test_objects a GROUP BY a.name', ` +
+                `args: ()`
+
+            object := &testObject{}
+            nameColumn, err := qt.ObjectColumn(object, "name")
+            Expect(err).ToNot(HaveOccurred())
+            idColumn, err := qt.ObjectColumn(object, "id")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(object).Select(
+                qt.LiteralSelectable("test_objects.name"),
+                qt.CountDistinctSelectable(idColumn, "ids"),
+                qt.SumSelectable(idColumn, "total"),
+                qt.MaxSelectable(idColumn, ""),
+            ).GroupBy(nameColumn)
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to read a scalar", func() {
+            object := &testObject{}
+            idColumn, err := qt.ObjectColumn(object, "id")
+            Expect(err).ToNot(HaveOccurred())
+
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `SELECT SUM\(a.id\) AS total FROM test_objects a`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{"total"}).AddRow(12),
+            )
+            mock.ExpectCommit()
+
+            var total int64
+            err = q.Join(object).Select(
+                qt.SumSelectable(idColumn, "total"),
+            ).Scalar(&total)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(total).To(BeEquivalentTo(12))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to read aggregates into structs", func() {
+            type nameCount struct {
+                Name string
+                Total int64 `db:"total"`
+            }
+            object := &testObject{}
+            nameColumn, err := qt.ObjectColumn(object, "name")
+            Expect(err).ToNot(HaveOccurred())
+            idColumn, err := qt.ObjectColumn(object, "id")
+            Expect(err).ToNot(HaveOccurred())
+
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `SELECT a.name, COUNT\(a.id\) AS total ` +
+                    `FROM test_objects a GROUP BY a.name`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{"name", "total"}).
+                    AddRow("Foo", 2).
+                    AddRow("Bar", nil),
+            )
+            mock.ExpectCommit()
+
+            var results []nameCount
+            err = q.Join(object).Select(
+                qt.LiteralSelectable("test_objects.name"),
+                qt.CountSelectable(idColumn, "total"),
+            ).GroupBy(nameColumn).Aggregate(&results)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(results).To(Equal([]nameCount{
+                {Name: "Foo", Total: 2},
+                {Name: "Bar", Total: 0},
+            }))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should keep aliased expressions out of objects", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name, ` +
+                    `COUNT\(a.id\) AS a_total FROM test_objects a ` +
+                    `GROUP BY a.id$`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{"a_id", "a_name", "a_total"}).
+                    AddRow(3, "carol", 2),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            idColumn := dot.ObjectColumn(object, "id")
+            results, err := q.Join(object).Select(
+                qt.BaseSelectable(object),
+                qt.CountSelectable(idColumn, "a_total"),
+            ).GroupBy(idColumn).Results()
+            Expect(err).ToNot(HaveOccurred())
+
+            Expect(results.Next()).To(BeTrue())
+            result := results.GetResult()
+            Expect(result.WriteTo(object)).To(Succeed())
+            Expect(object).To(Equal(&testObject{Id: 3, Name: "carol"}))
+            Expect(result.ExtraValues()).To(
+                HaveKeyWithValue("a_total", BeEquivalentTo(2)),
+            )
+            Expect(results.Close()).To(Succeed())
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to select distinct rows", func() {
+            expectedQuery := `query: 'SELECT DISTINCT a.id as a_id, ` +
+                `a.name as a_name ` +
+                `FROM second_test_objects b ` +
+                `JOIN test_objects a ON b.id=a.id', ` +
+                `args: ()`
+
+            object := &testObject{}
+            q.Join(object, &secondTestObject{}).Select(
+                qt.BaseSelectable(object),
+            ).Distinct()
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should only allow DISTINCT ON for postgres", func() {
+            object := &testObject{}
+            nameColumn, err := qt.ObjectColumn(object, "name")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(object).DistinctOn(nameColumn)
+
+            Expect(q.Errors).To(HaveLen(1))
+        })
+        It("should be able to select distinct on expressions", func() {
+            expectedQuery := `query: 'SELECT DISTINCT ON (a.name) ` +
+                `a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ORDER BY a.name, a.id', ` +
+                `args: ()`
+
+            connPool.Type = dbtype.Postgres
+            object := &testObject{}
+            nameColumn, err := qt.ObjectColumn(object, "name")
+            Expect(err).ToNot(HaveOccurred())
+            idColumn, err := qt.ObjectColumn(object, "id")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(object).DistinctOn(nameColumn).OrderBy(
+                nameColumn, idColumn,
+            )
+            Expect(q.Errors).To(BeEmpty())
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should count a single distinct expression", func() {
+            object := &testObject{}
+
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT COUNT\(DISTINCT a.name\) FROM test_objects a$`,
+            ).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+            mock.ExpectCommit()
+
+            count, err := q.Join(object).Select(
+                qt.LiteralSelectable("test_objects.name"),
+            ).Distinct().Limit(1).Count()
+            Expect(err).ToNot(HaveOccurred())
+            Expect(count).To(Equal(3))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should count distinct rows with a subquery", func() {
+            object := &testObject{}
+
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT COUNT\(\*\) FROM \(SELECT DISTINCT ` +
+                    `a.id as a_id, a.name as a_name ` +
+                    `FROM second_test_objects b ` +
+                    `JOIN test_objects a ON b.id=a.id\) counted_rows$`,
+            ).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+            mock.ExpectCommit()
+
+            count, err := q.Join(object, &secondTestObject{}).Select(
+                qt.BaseSelectable(object),
+            ).Distinct().Count()
+            Expect(err).ToNot(HaveOccurred())
+            Expect(count).To(Equal(2))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to filter with a subquery", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ` +
+                `WHERE (a.name = :const_4639577150595001395) AND ` +
+                `(a.id IN (SELECT s1a.test_object_id ` +
+                `FROM third_test_objects s1a ` +
+                `WHERE (s1a.active = :const_784298665860243217)))', ` +
+                `args: (const_4639577150595001395: "Foo", ` +
+                `const_784298665860243217: true)`
+
+            object := &testObject{}
+            subquery := NewQuery(connPool)
+            subqueryObject := &thirdTestObject{}
+            subquery.Join(subqueryObject).Select(
+                qt.LiteralSelectable("third_test_objects.test_object_id"),
+            ).Where(dot.Equal(
+                dot.ObjectColumn(subqueryObject, "active"), true,
+            ))
+
+            q.Join(object).Where(
+                dot.Equal(dot.ObjectColumn(object, "name"), "Foo"),
+                dot.In(
+                    dot.ObjectColumn(object, "id"), subquery.AsSubquery(),
+                ),
+            )
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to correlate a subquery", func() {
+            expectedQuery := `query: 'SELECT a.created_by as a_created_by, ` +
+                `a.id as a_id, a.updated_by as a_updated_by ` +
+                `FROM post_test_objects a ` +
+                `WHERE (NOT (EXISTS (SELECT 1 ` +
+                `FROM user_test_objects s1a ` +
+                `WHERE (s1a.id = a.created_by))))', ` +
+                `args: ()`
+
+            post := &postTestObject{}
+            user := &userTestObject{}
+            subquery := NewQuery(connPool)
+            subquery.Join(user).Select(qt.LiteralSelectable("1")).Where(
+                dot.Equal(
+                    dot.ObjectColumn(user, "id"),
+                    dot.ObjectColumn(post, "created_by"),
+                ),
+            )
+
+            q.Join(post).Where(dot.NotExists(subquery.AsSubquery()))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to select from a subquery", func() {
+            expectedQuery := `query: 'SELECT counts.created_by, ` +
+                `counts.total ` +
+                `FROM (SELECT s1a.created_by, COUNT(s1a.id) AS total ` +
+                `FROM post_test_objects s1a GROUP BY s1a.created_by) counts ` +
+                `WHERE (counts.total > :const_4639577150595001395)', ` +
+                `args: (const_4639577150595001395: 1)`
+
+            post := &postTestObject{}
+            subquery := NewQuery(connPool)
+            subquery.Join(post).Select(
+                qt.LiteralSelectable("post_test_objects.created_by"),
+                qt.CountSelectable(dot.ObjectColumn(post, "id"), "total"),
+            ).GroupBy(dot.ObjectColumn(post, "created_by"))
+
+            q.FromSubquery(subquery.AsSubquery(), "counts").Select(
+                qt.LiteralSelectable("counts.created_by"),
+                qt.LiteralSelectable("counts.total"),
+            ).Where(dot.GreaterThan(dot.AliasColumn("counts", "total"), 1))
+            Expect(q.Errors).To(BeEmpty())
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to use NULL-aware and pattern conditions", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ` +
+                `WHERE (a.name IS NULL) AND ` +
+                `(a.name LIKE :const_4639577150595001395) AND ` +
+                `(a.id BETWEEN :const_784298665860243217 AND ` +
+                `:const_1624540730452761730) AND ` +
+                `(a.id NOT IN (:const_6766514165631031403))', ` +
+                `args: (const_4639577150595001395: "Fo%", ` +
+                `const_784298665860243217: 1, ` +
+                `const_1624540730452761730: 5, ` +
+                `const_6766514165631031403: 3)`
+
+            object := &testObject{}
+            nameColumn := dot.ObjectColumn(object, "name")
+            idColumn := dot.ObjectColumn(object, "id")
+            q.Join(object).Where(
+                dot.Equal(nameColumn, nil),
+                dot.Like(nameColumn, "Fo%"),
+                dot.Between(idColumn, 1, 5),
+                dot.NotIn(idColumn, 3),
+            )
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to use raw SQL fragments", func() {
+            expectedQuery := `query: 'SELECT upper(a.name) AS loud ` +
+                `FROM test_objects a ` +
+                `WHERE lower(a.name) = :const_4639577150595001395 ` +
+                `ORDER BY length(a.name)', ` +
+                `args: (const_4639577150595001395: "foo")`
+
+            object := &testObject{}
+            nameColumn := dot.ObjectColumn(object, "name")
+            q.Join(object).Select(
+                dot.SelectAs(dot.Raw("upper(?)", nameColumn), "loud"),
+            ).Where(
+                dot.Raw("lower(?) = ?", nameColumn, "foo"),
+            ).OrderBy(dot.Raw("length(?)", nameColumn))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should fail to build mismatched raw SQL fragments", func() {
+            object := &testObject{}
+            q.Join(object).Where(dot.Raw("lower(?) = ?", "foo"))
+
+            _, _, err := q.buildQuery()
+            Expect(err).To(HaveOccurred())
+        })
+        It("should be able to select from a recursive CTE", func() {
+            expectedQuery := `query: 'WITH RECURSIVE tree AS (` +
+                `SELECT s1a.id, s1a.parent_id FROM tree_test_objects s1a ` +
+                `WHERE (s1a.parent_id IS NULL) UNION ALL ` +
+                `SELECT s2a.id, s2a.parent_id FROM tree_test_objects s2a ` +
+                `JOIN tree s2b ON (s2a.parent_id = s2b.id)) ` +
+                `SELECT a.id as a_id, a.parent_id as a_parent_id ` +
+                `FROM tree a', ` +
+                `args: ()`
+
+            root := &treeTestObject{}
+            anchor := NewQuery(connPool)
+            anchor.Join(root).Where(
+                dot.IsNull(dot.ObjectColumn(root, "parent_id")),
+            )
+
+            child := &treeTestObject{}
+            parent := CTE(&treeTestObject{}, "tree")
+            recursive := NewQuery(connPool)
+            recursive.Join(child).JoinOn(parent, dot.Equal(
+                dot.ObjectColumn(child, "parent_id"),
+                dot.ObjectColumn(parent, "id"),
+            )).Select(qt.BaseSelectable(child))
+
+            q.WithRecursive("tree", anchor, recursive).Join(
+                CTE(&treeTestObject{}, "tree"),
+            )
+            Expect(q.Errors).To(BeEmpty())
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to write CTE results into models", func() {
+            active := &thirdTestObject{}
+            activeQuery := NewQuery(connPool)
+            activeQuery.Join(active).Where(
+                dot.Equal(dot.ObjectColumn(active, "active"), true),
+            )
+
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^WITH active_objects AS \(SELECT s1a.active, s1a.id, ` +
+                    `s1a.test_object_id FROM third_test_objects s1a ` +
+                    `WHERE \(s1a.active = \?\)\) ` +
+                    `SELECT a.active as a_active, a.id as a_id, ` +
+                    `a.test_object_id as a_test_object_id ` +
+                    `FROM active_objects a$`,
+            ).WithArgs(true).WillReturnRows(
+                sqlmock.NewRows(
+                    []string{"a_active", "a_id", "a_test_object_id"},
+                ).AddRow(true, 1, 5).AddRow(true, 2, 6),
+            )
+            mock.ExpectCommit()
+
+            results, err := q.With("active_objects", activeQuery).Join(
+                CTE(&thirdTestObject{}, "active_objects"),
+            ).Results()
+            Expect(err).ToNot(HaveOccurred())
+
+            var objects []*thirdTestObject
+            err = results.WriteAllTo(&objects)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(objects).To(Equal([]*thirdTestObject{
+                {Id: 1, TestObjectId: 5, Active: true},
+                {Id: 2, TestObjectId: 6, Active: true},
+            }))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to combine queries", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ` +
+                `WHERE (a.id = :const_4639577150595001395) ` +
+                `UNION ALL SELECT s1a.id as s1a_id, s1a.name as s1a_name ` +
+                `FROM test_objects s1a ` +
+                `WHERE (s1a.id = :const_784298665860243217) ` +
+                `ORDER BY a_name DESC, a_id LIMIT 5', ` +
+                `args: (const_4639577150595001395: 1, ` +
+                `const_784298665860243217: 2)`
+
+            object := &testObject{}
+            other := &testObject{}
+            otherQuery := NewQuery(connPool)
+            otherQuery.Join(other).Where(
+                dot.Equal(dot.ObjectColumn(other, "id"), 2),
+            )
+            q.Join(object).Where(
+                dot.Equal(dot.ObjectColumn(object, "id"), 1),
+            ).UnionAll(otherQuery).OrderBy(
+                dot.Desc(dot.ObjectColumn(object, "name")),
+                dot.ObjectColumn(object, "id"),
+            ).Limit(5)
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should check combined queries select as many columns", func() {
+            object := &testObject{}
+            other := &testObject{}
+            otherQuery := NewQuery(connPool)
+            otherQuery.Join(other).Select(
+                qt.LiteralSelectable("test_objects.name"),
+            )
+            q.Join(object).Except(otherQuery)
+
+            _, _, err := q.buildQuery()
+            Expect(err).To(MatchError(ContainSubstring(
+                "Query selects 2 columns but EXCEPT query selects 1",
+            )))
+        })
+        It("should check combined queries select the same columns", func() {
+            object := &testObject{}
+            other := &testObject{}
+            otherQuery := NewQuery(connPool)
+            otherQuery.Join(other).Select(
+                qt.LiteralSelectable("test_objects.name"),
+                qt.LiteralSelectable("test_objects.id"),
+            )
+            q.Join(object).Union(otherQuery)
+
+            _, _, err := q.buildQuery()
+            Expect(err).To(MatchError(ContainSubstring(
+                "Query selects id as column 1 but UNION query selects name",
+            )))
+        })
+        It("should be able to filter on a window function", func() {
+            expectedQuery := `query: 'SELECT ranked.id, ranked.created_by ` +
+                `FROM (SELECT s1a.id, s1a.created_by, ROW_NUMBER() OVER ` +
+                `(PARTITION BY s1a.created_by ORDER BY s1a.id DESC) ` +
+                `AS position FROM post_test_objects s1a) ranked ` +
+                `WHERE (ranked.position <= :const_4639577150595001395)', ` +
+                `args: (const_4639577150595001395: 3)`
+
+            post := &postTestObject{}
+            subquery := NewQuery(connPool)
+            subquery.Join(post).Select(
+                qt.LiteralSelectable("post_test_objects.id"),
+                qt.LiteralSelectable("post_test_objects.created_by"),
+                dot.RowNumber().PartitionBy(
+                    dot.ObjectColumn(post, "created_by"),
+                ).OrderBy(
+                    dot.Desc(dot.ObjectColumn(post, "id")),
+                ).As("position"),
+            )
+
+            q.FromSubquery(subquery.AsSubquery(), "ranked").Select(
+                qt.LiteralSelectable("ranked.id"),
+                qt.LiteralSelectable("ranked.created_by"),
+            ).Where(dot.LessThanEqual(
+                dot.AliasColumn("ranked", "position"), 3,
+            ))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to page after a cursor", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ` +
+                `WHERE ((a.name < :const_4639577150595001395) OR ` +
+                `((a.name = :const_784298665860243217) AND ` +
+                `(a.id > :const_1624540730452761730))) ` +
+                `ORDER BY a.name DESC, a.id ASC LIMIT 10', ` +
+                `args: (const_4639577150595001395: "bob", ` +
+                `const_784298665860243217: "bob", ` +
+                `const_1624540730452761730: 7)`
+
+            cursor, err := qt.EncodeCursor([]interface{}{"bob", 7})
+            Expect(err).ToNot(HaveOccurred())
+
+            object := &testObject{}
+            q.Join(object).OrderBy(
+                dot.Desc(dot.ObjectColumn(object, "name")),
+                dot.Asc(dot.ObjectColumn(object, "id")),
+            ).After(cursor).Limit(10)
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to page before a cursor", func() {
+            expectedQuery := `query: 'SELECT * FROM (` +
+                `SELECT a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ` +
+                `WHERE ((a.name > :const_4639577150595001395) OR ` +
+                `((a.name = :const_784298665860243217) AND ` +
+                `(a.id < :const_1624540730452761730))) ` +
+                `ORDER BY a.name ASC, a.id DESC LIMIT 10) keyset_page ` +
+                `ORDER BY a_name DESC, a_id ASC', ` +
+                `args: (const_4639577150595001395: "bob", ` +
+                `const_784298665860243217: "bob", ` +
+                `const_1624540730452761730: 7)`
+
+            cursor, err := qt.EncodeCursor([]interface{}{"bob", 7})
+            Expect(err).ToNot(HaveOccurred())
+
+            object := &testObject{}
+            q.Join(object).OrderBy(
+                dot.Desc(dot.ObjectColumn(object, "name")),
+                dot.Asc(dot.ObjectColumn(object, "id")),
+            ).Before(cursor).Limit(10)
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should check cursors match the ordering", func() {
+            cursor, err := qt.EncodeCursor([]interface{}{7})
+            Expect(err).ToNot(HaveOccurred())
+
+            object := &testObject{}
+            q.Join(object).OrderBy(
+                dot.ObjectColumn(object, "name"),
+                dot.ObjectColumn(object, "id"),
+            ).After(cursor)
+
+            _, _, err = q.buildQuery()
+            Expect(err).To(MatchError(ContainSubstring(
+                "Cursor has 1 values but the query is ordered by 2 columns",
+            )))
+        })
+        It("should check cursor values match the ordering's types", func() {
+            cursor, err := qt.EncodeCursor([]interface{}{7, "bob"})
+            Expect(err).ToNot(HaveOccurred())
+
+            object := &testObject{}
+            q.Join(object).OrderBy(
+                dot.ObjectColumn(object, "name"),
+                dot.ObjectColumn(object, "id"),
+            ).After(cursor)
+
+            _, _, err = q.buildQuery()
+            Expect(err).To(MatchError(ContainSubstring(
+                "Cursor value #0 for 'a.name' should be string but is int64",
+            )))
+        })
+        It("should refuse tampered cursors", func() {
+            object := &testObject{}
+            q.Join(object).OrderBy(
+                dot.ObjectColumn(object, "id"),
+            ).After("eyJ0IjoiaW50IiwidiI6MX0")
+
+            Expect(q.Errors).To(HaveLen(1))
+            Expect(q.Errors[0]).To(MatchError(ContainSubstring(
+                "Error while reading keyset cursor",
+            )))
+        })
+        It("should return a cursor for the last row written", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name ` +
+                    `FROM test_objects a ` +
+                    `ORDER BY a.name DESC, a.id LIMIT 2$`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{"a_id", "a_name"}).
+                    AddRow(3, "carol").AddRow(7, "bob"),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            results, err := q.Join(object).OrderBy(
+                dot.Desc(dot.ObjectColumn(object, "name")),
+                dot.ObjectColumn(object, "id"),
+            ).Limit(2).Results()
+            Expect(err).ToNot(HaveOccurred())
+
+            var objects []*testObject
+            err = results.WriteAllTo(&objects)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(objects).To(HaveLen(2))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+
+            cursor, err := results.Cursor()
+            Expect(err).ToNot(HaveOccurred())
+            values, err := qt.DecodeCursor(cursor)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(values).To(Equal([]interface{}{"bob", int64(7)}))
+        })
+        It("should be able to paginate results", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT COUNT\(\*\) FROM test_objects a$`,
+            ).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name ` +
+                    `FROM test_objects a ORDER BY a.id LIMIT 2 OFFSET 2$`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{"a_id", "a_name"}).
+                    AddRow(3, "carol").AddRow(4, "dave"),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            q.Join(object).OrderBy(dot.ObjectColumn(object, "id"))
+
+            var objects []*testObject
+            pageInfo, err := q.Paginate(2, 2, &objects)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(pageInfo).To(Equal(PageInfo{
+                Page: 2,
+                PerPage: 2,
+                Total: 5,
+                Pages: 3,
+                HasNext: true,
+                HasPrev: true,
+            }))
+            Expect(objects).To(Equal([]*testObject{
+                {Id: 3, Name: "carol"},
+                {Id: 4, Name: "dave"},
+            }))
+            Expect(q.OptionClauses).To(HaveLen(1))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to lock rows", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, a.name as a_name ` +
+                `FROM second_test_objects b ` +
+                `JOIN test_objects a ON b.id=a.id ` +
+                `ORDER BY a.id LIMIT 5 FOR UPDATE OF a SKIP LOCKED', ` +
+                `args: ()`
+
+            object := &testObject{}
+            q.Join(object, &secondTestObject{}).Select(
+                qt.BaseSelectable(object),
+            ).OrderBy(
+                dot.ObjectColumn(object, "id"),
+            ).ForShare().ForUpdate().Of(object).SkipLocked().Limit(5)
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should hold row locks in the caller's transaction", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name ` +
+                    `FROM test_objects a WHERE \(a.id = \?\) ` +
+                    `LIMIT 1 FOR UPDATE$`,
+            ).WithArgs(3).WillReturnRows(
+                sqlmock.NewRows([]string{"a_id", "a_name"}).
+                    AddRow(3, "carol"),
+            )
+            mock.ExpectExec(
+                `^UPDATE test_objects SET name = \? WHERE id = \?$`,
+            ).WithArgs("dave", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+            mock.ExpectCommit()
+
+            tx, err := connPool.Beginx()
+            Expect(err).ToNot(HaveOccurred())
+
+            object := &testObject{}
+            err = q.Join(object).Where(
+                dot.Equal(dot.ObjectColumn(object, "id"), 3),
+            ).ForUpdate().InTx(tx).First(object)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(object).To(Equal(&testObject{Id: 3, Name: "carol"}))
+
+            _, err = tx.Exec(
+                "UPDATE test_objects SET name = ? WHERE id = ?",
+                "dave",
+                object.Id,
+            )
+            Expect(err).ToNot(HaveOccurred())
+            Expect(tx.Commit()).To(Succeed())
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should require a transaction to lock rows", func() {
+            object := &testObject{}
+            err := q.Join(object).ForUpdate().First(object)
+            Expect(err).To(MatchError(ContainSubstring(
+                "Locking rows requires running the query in a transaction",
+            )))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should refuse to lock rows while paginating", func() {
+            object := &testObject{}
+            var objects []*testObject
+            _, err := q.Join(object).ForShare().Paginate(1, 10, &objects)
+            Expect(err).To(MatchError(
+                "Rows can't be locked while paginating",
+            ))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should refuse locks the database can't take", func() {
+            newQuery := func() (*Query, *testObject) {
+                object := &testObject{}
+                query := NewQuery(connPool)
+                query.Join(object)
+                return query, object
+            }
+
+            distinct, _ := newQuery()
+            distinct.Distinct()
+
+            grouped, groupedObject := newQuery()
+            grouped.GroupBy(dot.ObjectColumn(groupedObject, "name"))
+
+            aggregate, aggregateObject := newQuery()
+            aggregate.Select(qt.CountSelectable(
+                dot.ObjectColumn(aggregateObject, "id"), "total",
+            ))
+
+            combined, _ := newQuery()
+            other := NewQuery(connPool)
+            other.Join(&testObject{})
+            combined.Union(other)
+
+            refusals := map[*Query]string{
+                distinct: "DISTINCT",
+                grouped: "GROUP BY or HAVING",
+                aggregate: "aggregate functions",
+                combined: "set operations",
+            }
+            for query, refusal := range refusals {
+                query.ForUpdate()
+                _, _, err := query.buildQuery()
+                Expect(err).To(MatchError(
+                    "Rows can't be locked in a query with " + refusal,
+                ))
+            }
+        })
+        It("should refuse to lock the nullable side of outer joins", func() {
+            object := &testObject{}
+            object2 := &secondTestObject{}
+            q.Join(object).LeftJoin(object2).ForUpdate()
+
+            _, _, err := q.buildQuery()
+            Expect(err).To(MatchError(ContainSubstring(
+                "Rows of 'b' can't be locked since it's on the nullable " +
+                    "side of an outer join",
+            )))
+
+            q.Of(object)
+            queryString, _, err := q.buildQuery()
+            Expect(err).ToNot(HaveOccurred())
+            Expect(queryString).To(HaveSuffix("FOR UPDATE OF a"))
+        })
+        It("should require a lock for lock modifiers", func() {
+            object := &testObject{}
+            q.Join(object).NoWait()
+
+            Expect(q.Errors).To(HaveLen(1))
+            Expect(q.Errors[0]).To(MatchError(ContainSubstring(
+                "NoWait requires a lock",
+            )))
+        })
+        It("should be able to read the first result", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name ` +
+                    `FROM test_objects a ORDER BY a.id LIMIT 1$`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{"a_id", "a_name"}).
+                    AddRow(3, "carol"),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            err := q.Join(object).OrderBy(
+                dot.ObjectColumn(object, "id"),
+            ).First(object)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(object).To(Equal(&testObject{Id: 3, Name: "carol"}))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should report when there's no first result", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name ` +
+                    `FROM test_objects a LIMIT 1$`,
+            ).WillReturnRows(sqlmock.NewRows([]string{"a_id", "a_name"}))
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            err := q.Join(object).First(object)
+            Expect(err).To(Equal(NotFoundError))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should require a single result for One", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name ` +
+                    `FROM test_objects a LIMIT 2$`,
+            ).WillReturnRows(
+                sqlmock.NewRows([]string{"a_id", "a_name"}).
+                    AddRow(3, "carol").AddRow(4, "dave"),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            err := q.Join(object).One(object)
+            Expect(err).To(Equal(MultipleResultsError))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to check if there are results", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT EXISTS\(SELECT a.id as a_id, a.name as a_name ` +
+                    `FROM test_objects a WHERE \(a.name = \?\)\)$`,
+            ).WithArgs("carol").WillReturnRows(
+                sqlmock.NewRows([]string{"exists"}).AddRow(true),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            exists, err := q.Join(object).Where(
+                dot.Equal(dot.ObjectColumn(object, "name"), "carol"),
+            ).Exists()
+            Expect(err).ToNot(HaveOccurred())
+            Expect(exists).To(BeTrue())
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to pluck a column", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id FROM test_objects a ` +
+                    `WHERE \(a.name LIKE \?\) ORDER BY a.id$`,
+            ).WithArgs("c%").WillReturnRows(
+                sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(9),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            var ids []int64
+            err := q.Join(object).Where(
+                dot.Like(dot.ObjectColumn(object, "name"), "c%"),
+            ).OrderBy(
+                dot.ObjectColumn(object, "id"),
+            ).Pluck(dot.ObjectColumn(object, "id"), &ids)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(ids).To(Equal([]int64{3, 9}))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should refuse to pluck from combined queries", func() {
+            object := &testObject{}
+            other := &testObject{}
+            otherQuery := NewQuery(connPool)
+            otherQuery.Join(other)
+            q.Join(object).Union(otherQuery)
+
+            var ids []int64
+            err := q.Pluck(dot.ObjectColumn(object, "id"), &ids)
+            Expect(err).To(MatchError(
+                "Can't pluck a column from a query with set operations",
+            ))
+            Expect(ids).To(BeEmpty())
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
+        It("should be able to join with an explicit condition", func() {
+            expectedQuery := `query: 'SELECT a.id as a_id, a.name as a_name ` +
+                `FROM test_objects a ` +
+                `JOIN third_test_objects b ` +
+                `ON (a.id = b.test_object_id) AND ` +
+                `(b.active = :const_4639577150595001395)', ` +
+                `args: (const_4639577150595001395: true)`
+
+            object := &testObject{}
+            object2 := &thirdTestObject{}
+
+            objColumn, err := qt.ObjectColumn(object, "id")
+            Expect(err).ToNot(HaveOccurred())
+            fkeyColumn, err := qt.ObjectColumn(object2, "test_object_id")
+            Expect(err).ToNot(HaveOccurred())
+            activeColumn, err := qt.ObjectColumn(object2, "active")
+            Expect(err).ToNot(HaveOccurred())
+            q.Join(object).JoinOn(object2, qt.NewMultiAndCondition(
+                qt.NewDefaultCondition(
+                    objColumn, fkeyColumn, qt.EqualCombiner,
+                ),
+                qt.NewDefaultCondition(
+                    activeColumn,
+                    qt.InterfaceToQueryable(true),
+                    qt.EqualCombiner,
+                ),
+            )).Select(qt.BaseSelectable(object))
+
+            queryString := q.PrintQuery()
+            fmt.Fprint(GinkgoWriter, queryString)
+            Expect(queryString).To(Equal(expectedQuery))
+        })
+        It("should be able to left join with an explicit condition", func() {
+            mock.ExpectBegin()
+            mock.ExpectQuery(
+                `^SELECT a.id as a_id, a.name as a_name, ` +
+                    `b.active as b_active, b.id as b_id, ` +
+                    `b.test_object_id as b_test_object_id ` +
+                    `FROM test_objects a ` +
+                    `LEFT JOIN third_test_objects b ` +
+                    `ON \(a.id = b.test_object_id\) AND ` +
+                    `\(b.active = \?\)$`,
+            ).WithArgs(true).WillReturnRows(
+                sqlmock.NewRows([]string{
+                    "a_id", "a_name", "b_active", "b_id", "b_test_object_id",
+                }).AddRow(1, "foo", true, 5, 1).
+                    AddRow(2, "bar", nil, nil, nil),
+            )
+            mock.ExpectCommit()
+
+            object := &testObject{}
+            object2 := &thirdTestObject{}
+            results, err := q.Join(object).LeftJoinOn(object2, dot.And(
+                dot.Equal(
+                    dot.ObjectColumn(object, "id"),
+                    dot.ObjectColumn(object2, "test_object_id"),
+                ),
+                dot.Equal(dot.ObjectColumn(object2, "active"), true),
+            )).Results()
+            Expect(err).ToNot(HaveOccurred())
+
+            var objects []*testObject
+            var objects2 []*thirdTestObject
+            err = results.WriteAllTo(&objects, &objects2)
+            Expect(err).ToNot(HaveOccurred())
+            Expect(objects).To(HaveLen(2))
+            Expect(objects2).To(Equal([]*thirdTestObject{
+                {Id: 5, TestObjectId: 1, Active: true},
+                nil,
+            }))
+            Expect(mock.ExpectationsWereMet()).To(Succeed())
+        })
     })
 })
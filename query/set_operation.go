@@ -0,0 +1,228 @@
+package query
+
+import (
+    "fmt"
+
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// setOperation combines the results of another query with a query.
+type setOperation struct {
+    keyword string
+    subquery *Subquery
+}
+
+func (self *Query) combine(keyword string, other *Query) *Query {
+    self.cached.Options.invalidate()
+
+    if other == nil {
+        self.Errors = append(self.Errors, errors.Errorf(
+            "Can't %s with a nil query", keyword,
+        ))
+        return self
+    }
+
+    self.setOperations = append(self.setOperations, setOperation{
+        keyword: keyword,
+        subquery: other.AsSubquery(),
+    })
+
+    return self
+}
+
+// Union combines the distinct results of the other query with this one. Any
+// ordering, limit or offset of this query applies to the combined results.
+func (self *Query) Union(other *Query) *Query {
+    return self.combine("UNION", other)
+}
+
+// UnionAll combines all the results of the other query with this one
+// including duplicates. Any ordering, limit or offset of this query applies to
+// the combined results.
+func (self *Query) UnionAll(other *Query) *Query {
+    return self.combine("UNION ALL", other)
+}
+
+// Intersect keeps only the results of this query which the other query also
+// returns. Any ordering, limit or offset of this query applies to the
+// combined results.
+func (self *Query) Intersect(other *Query) *Query {
+    return self.combine("INTERSECT", other)
+}
+
+// Except keeps only the results of this query which the other query doesn't
+// return. Any ordering, limit or offset of this query applies to the combined
+// results.
+func (self *Query) Except(other *Query) *Query {
+    return self.combine("EXCEPT", other)
+}
+
+// buildSetOperations builds the set operations of the query checking that
+// every query selects the same columns in the same order as this one. Columns
+// are compared by name without their table so expressions have to be given
+// matching aliases to be compared.
+func (self Query) buildSetOperations() (string, []interface{}, error) {
+    columns, _, err := self.selectColumns()
+    if err != nil {
+        return "", nil, err
+    }
+
+    var (
+        queryString string
+        args []interface{}
+    )
+    for _, operation := range self.setOperations {
+        other, err := operation.subquery.prepare(self.Tables)
+        if err != nil {
+            return "", nil, err
+        }
+        otherColumns, _, err := other.selectColumns()
+        if err != nil {
+            return "", nil, errors.Wrapf(
+                err, "Error while building %s query", operation.keyword,
+            )
+        }
+        if len(otherColumns) != len(columns) {
+            return "", nil, errors.Errorf(
+                "Query selects %d columns but %s query selects %d",
+                len(columns),
+                operation.keyword,
+                len(otherColumns),
+            )
+        }
+        for i, column := range columns {
+            otherColumn := otherColumns[i]
+            if column.column == "" || otherColumn.column == "" {
+                continue
+            }
+            if column.column != otherColumn.column {
+                return "", nil, errors.Errorf(
+                    "Query selects %s as column %d but %s query selects %s",
+                    column.column,
+                    i+1,
+                    operation.keyword,
+                    otherColumn.column,
+                )
+            }
+        }
+
+        otherString, otherArgs, err := other.buildQuery()
+        if err != nil {
+            return "", nil, errors.Wrapf(
+                err, "Error while building %s query", operation.keyword,
+            )
+        }
+        if other.needsParens() {
+            otherString = fmt.Sprintf("(%s)", otherString)
+        }
+        queryString += fmt.Sprintf(" %s %s", operation.keyword, otherString)
+        args = append(args, otherArgs...)
+    }
+
+    return queryString, args, nil
+}
+
+// needsParens checks if the query has to be wrapped in parenthesis to be
+// combined with another query.
+func (self Query) needsParens() bool {
+    if len(self.commonTableExpressions) != 0 {
+        return true
+    }
+    for _, option := range self.OptionClauses {
+        switch option.OptionType() {
+        case qtypes.OrderByOptionType,
+            qtypes.LimitOptionType,
+            qtypes.OffsetOptionType:
+            return true
+        }
+    }
+
+    return false
+}
+
+// buildCombinedOptions builds the ordering, limit and offset which apply to
+// the combined results of the set operations. The results of set operations
+// can only be ordered by their column names so any ordering which matches a
+// selected column is written as that column's name.
+func (self Query) buildCombinedOptions() (string, []interface{}, error) {
+    columns, _, err := self.selectColumns()
+    if err != nil {
+        return "", nil, err
+    }
+    names := make(map[string]string, len(columns))
+    for _, column := range columns {
+        if column.name != "" {
+            names[column.expression] = column.name
+        }
+    }
+
+    var options []qtypes.QueryOption
+    for _, option := range self.OptionClauses {
+        switch option.OptionType() {
+        case qtypes.OrderByOptionType:
+            orderOption := option.(qtypes.OrderByOption)
+            orderOption.Order = outputColumns(orderOption.Order, names)
+            options = append(options, orderOption)
+        case qtypes.LimitOptionType, qtypes.OffsetOptionType:
+            options = append(options, option)
+        }
+    }
+
+    optionQuery, args := self.optionsQuery(options)
+    return optionQuery, args, nil
+}
+
+// outputColumnQueryable renders a statement as the name of the selected
+// column it matches if there is one.
+type outputColumnQueryable struct {
+    statement qtypes.Queryable
+    names map[string]string
+}
+
+func (self outputColumnQueryable) String() string {
+    return self.statement.String()
+}
+
+func (self outputColumnQueryable) QueryValue(
+    at *qtypes.AliasedTables,
+) (string, []interface{}) {
+    queryString, args := self.statement.QueryValue(at)
+    if name, ok := self.names[queryString]; ok {
+        return name, args
+    }
+
+    return queryString, args
+}
+
+// outputColumns rewrites the provided ordering statements to use the names
+// of the selected columns they match.
+func outputColumns(
+    statement qtypes.Queryable, names map[string]string,
+) qtypes.Queryable {
+    switch typedStatement := statement.(type) {
+    case qtypes.MultiCondition:
+        values := make([]qtypes.Queryable, len(typedStatement.Values))
+        for i, value := range typedStatement.Values {
+            values[i] = outputColumns(value, names)
+        }
+        typedStatement.Values = values
+        return typedStatement
+    case qtypes.AscendingQueryable:
+        typedStatement.Statement = outputColumns(
+            typedStatement.Statement, names,
+        )
+        return typedStatement
+    case qtypes.DescendingQueryable:
+        typedStatement.Statement = outputColumns(
+            typedStatement.Statement, names,
+        )
+        return typedStatement
+    }
+
+    return outputColumnQueryable{
+        statement: statement,
+        names: names,
+    }
+}
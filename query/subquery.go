@@ -0,0 +1,126 @@
+package query
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/pkg/errors"
+
+    "github.com/daihasso/machgo/query/qtypes"
+)
+
+// Subquery is a Query embedded in another query. It can be used as a
+// Queryable anywhere a value is expected (for example with dot.In, dot.Exists
+// or a comparison) or as a derived table with Query.FromSubquery.
+//
+// The aliases generated for the subquery's objects are prefixed when it's
+// embedded so they're kept separate from those of the enclosing query.
+// Objects of the enclosing query can still be referenced in the subquery to
+// correlate the two.
+type Subquery struct {
+    query Query
+    plainColumns bool
+}
+
+// AsSubquery creates a Subquery from the query so that it can be embedded in
+// another query.
+func (self Query) AsSubquery() *Subquery {
+    return &Subquery{
+        query: self,
+    }
+}
+
+// prepare creates the copy of the query to embed in the query using the
+// provided tables (or the query itself if there are none).
+func (self *Subquery) prepare(at *qtypes.AliasedTables) (Query, error) {
+    if len(self.query.Errors) != 0 {
+        return Query{}, errors.Errorf(
+            "Errors while forming subquery:\n%#+v",
+            self.query.Errors,
+        )
+    }
+
+    query := self.query
+    if at != nil {
+        query = self.query.nested(at, at.SubqueryPrefix(self))
+    }
+    query.plainColumns = self.plainColumns
+
+    return query, nil
+}
+
+func (self *Subquery) build(
+    at *qtypes.AliasedTables,
+) (string, []interface{}, error) {
+    query, err := self.prepare(at)
+    if err != nil {
+        return "", nil, err
+    }
+    queryString, args, err := query.buildQuery()
+    if err != nil {
+        return "", nil, errors.Wrap(err, "Error while building subquery")
+    }
+
+    return queryString, args, nil
+}
+
+func (self *Subquery) String() string {
+    queryString, _, err := self.build(nil)
+    if err != nil {
+        return fmt.Sprintf("(Error building subquery: '%s')", err.Error())
+    }
+
+    return fmt.Sprintf("(%s)", queryString)
+}
+
+// QueryValue renders the subquery wrapped in parenthesis. Errors building the
+// subquery are reported to the provided AliasedTables.
+func (self *Subquery) QueryValue(
+    at *qtypes.AliasedTables,
+) (string, []interface{}) {
+    queryString, args, err := self.build(at)
+    if err != nil {
+        at.ReportError(err)
+        return "()", nil
+    }
+
+    return fmt.Sprintf("(%s)", queryString), args
+}
+
+// nested creates a copy of the query for embedding in the query using the
+// provided tables with its generated aliases prefixed.
+func (self Query) nested(
+    parent *qtypes.AliasedTables, prefix string,
+) Query {
+    tables, rename := self.Tables.Nested(parent, prefix)
+
+    nested := self
+    nested.Tables = tables
+    nested.cached = cachedQuery{}
+
+    nested.joinTypes = make(map[string]qtypes.JoinType, len(self.joinTypes))
+    for alias, joinType := range self.joinTypes {
+        nested.joinTypes[rename(alias)] = joinType
+    }
+    nested.joinConditions = make(
+        map[string]qtypes.Queryable, len(self.joinConditions),
+    )
+    for alias, condition := range self.joinConditions {
+        nested.joinConditions[rename(alias)] = condition
+    }
+    nested.joinVia = make(map[string]string, len(self.joinVia))
+    for alias, name := range self.joinVia {
+        nested.joinVia[rename(alias)] = name
+    }
+    nested.intermediateAliases = make(
+        map[string]string, len(self.intermediateAliases),
+    )
+    for key, alias := range self.intermediateAliases {
+        // Keys are in the form fromAlias:targetTable.
+        parts := strings.SplitN(key, ":", 2)
+        parts[0] = rename(parts[0])
+        nested.intermediateAliases[strings.Join(parts, ":")] = rename(alias)
+    }
+
+    return nested
+}
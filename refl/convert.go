@@ -0,0 +1,430 @@
+package refl
+
+import (
+    "database/sql"
+    "database/sql/driver"
+    "encoding/hex"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+
+    "github.com/pkg/errors"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+func isByteSlice(typ reflect.Type) bool {
+    return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
+
+func isByteArray(typ reflect.Type) bool {
+    return typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8
+}
+
+// valueFromValuer unwraps driver.Valuers into the value they provide to the
+// database.
+func valueFromValuer(val reflect.Value) (reflect.Value, error) {
+    if !val.Type().Implements(valuerType) {
+        return val, nil
+    }
+    if val.Kind() == reflect.Ptr && val.IsNil() {
+        return reflect.Value{}, nil
+    }
+
+    driverValue, err := val.Interface().(driver.Valuer).Value()
+    if err != nil {
+        return reflect.Value{}, errors.Wrap(
+            err, "Error getting value from driver.Valuer",
+        )
+    }
+    if driverValue == nil {
+        return reflect.Value{}, nil
+    }
+
+    return reflect.ValueOf(driverValue), nil
+}
+
+// stringFromValue tries to represent a value as a string.
+func stringFromValue(val reflect.Value) (string, bool) {
+    typ := val.Type()
+    switch {
+    case typ.Kind() == reflect.String:
+        return val.String(), true
+    case isByteSlice(typ):
+        return string(val.Bytes()), true
+    case typ.Implements(stringerType):
+        return val.Interface().(fmt.Stringer).String(), true
+    }
+
+    return "", false
+}
+
+func parseUUIDLike(in string, size int) ([]byte, bool) {
+    cleaned := strings.Replace(in, "-", "", -1)
+    if len(cleaned) != size*2 {
+        return nil, false
+    }
+    decoded, err := hex.DecodeString(cleaned)
+    if err != nil {
+        return nil, false
+    }
+
+    return decoded, true
+}
+
+func formatUUIDLike(raw []byte) string {
+    encoded := hex.EncodeToString(raw)
+    if len(raw) != 16 {
+        return encoded
+    }
+
+    return fmt.Sprintf(
+        "%s-%s-%s-%s-%s",
+        encoded[0:8],
+        encoded[8:12],
+        encoded[12:16],
+        encoded[16:20],
+        encoded[20:32],
+    )
+}
+
+func setInt(fieldVal, newVal reflect.Value) error {
+    var intValue int64
+    switch newVal.Kind() {
+    case
+        reflect.Int,
+        reflect.Int8,
+        reflect.Int16,
+        reflect.Int32,
+        reflect.Int64:
+        intValue = newVal.Int()
+    case
+        reflect.Uint,
+        reflect.Uint8,
+        reflect.Uint16,
+        reflect.Uint32,
+        reflect.Uint64:
+        uintValue := newVal.Uint()
+        if uintValue > uint64(1<<63-1) {
+            return errors.Errorf(
+                "Value %d overflows %s", uintValue, fieldVal.Type(),
+            )
+        }
+        intValue = int64(uintValue)
+    case reflect.Float32, reflect.Float64:
+        floatValue := newVal.Float()
+        if floatValue != float64(int64(floatValue)) {
+            return errors.Errorf(
+                "Value %v can't be represented by %s",
+                floatValue,
+                fieldVal.Type(),
+            )
+        }
+        intValue = int64(floatValue)
+    default:
+        stringValue, ok := stringFromValue(newVal)
+        if !ok {
+            return errors.Errorf(
+                "Can't convert %s to %s", newVal.Type(), fieldVal.Type(),
+            )
+        }
+        parsed, err := strconv.ParseInt(stringValue, 10, 64)
+        if err != nil {
+            return errors.Wrapf(
+                err, "Can't convert '%s' to %s", stringValue, fieldVal.Type(),
+            )
+        }
+        intValue = parsed
+    }
+
+    if fieldVal.OverflowInt(intValue) {
+        return errors.Errorf(
+            "Value %d overflows %s", intValue, fieldVal.Type(),
+        )
+    }
+    fieldVal.SetInt(intValue)
+
+    return nil
+}
+
+func setUint(fieldVal, newVal reflect.Value) error {
+    var uintValue uint64
+    switch newVal.Kind() {
+    case
+        reflect.Int,
+        reflect.Int8,
+        reflect.Int16,
+        reflect.Int32,
+        reflect.Int64:
+        intValue := newVal.Int()
+        if intValue < 0 {
+            return errors.Errorf(
+                "Negative value %d can't be stored in %s",
+                intValue,
+                fieldVal.Type(),
+            )
+        }
+        uintValue = uint64(intValue)
+    case
+        reflect.Uint,
+        reflect.Uint8,
+        reflect.Uint16,
+        reflect.Uint32,
+        reflect.Uint64:
+        uintValue = newVal.Uint()
+    default:
+        stringValue, ok := stringFromValue(newVal)
+        if !ok {
+            return errors.Errorf(
+                "Can't convert %s to %s", newVal.Type(), fieldVal.Type(),
+            )
+        }
+        parsed, err := strconv.ParseUint(stringValue, 10, 64)
+        if err != nil {
+            return errors.Wrapf(
+                err, "Can't convert '%s' to %s", stringValue, fieldVal.Type(),
+            )
+        }
+        uintValue = parsed
+    }
+
+    if fieldVal.OverflowUint(uintValue) {
+        return errors.Errorf(
+            "Value %d overflows %s", uintValue, fieldVal.Type(),
+        )
+    }
+    fieldVal.SetUint(uintValue)
+
+    return nil
+}
+
+func setFloat(fieldVal, newVal reflect.Value) error {
+    var floatValue float64
+    switch newVal.Kind() {
+    case
+        reflect.Int,
+        reflect.Int8,
+        reflect.Int16,
+        reflect.Int32,
+        reflect.Int64:
+        floatValue = float64(newVal.Int())
+    case
+        reflect.Uint,
+        reflect.Uint8,
+        reflect.Uint16,
+        reflect.Uint32,
+        reflect.Uint64:
+        floatValue = float64(newVal.Uint())
+    case reflect.Float32, reflect.Float64:
+        floatValue = newVal.Float()
+    default:
+        stringValue, ok := stringFromValue(newVal)
+        if !ok {
+            return errors.Errorf(
+                "Can't convert %s to %s", newVal.Type(), fieldVal.Type(),
+            )
+        }
+        parsed, err := strconv.ParseFloat(stringValue, 64)
+        if err != nil {
+            return errors.Wrapf(
+                err, "Can't convert '%s' to %s", stringValue, fieldVal.Type(),
+            )
+        }
+        floatValue = parsed
+    }
+
+    if fieldVal.OverflowFloat(floatValue) {
+        return errors.Errorf(
+            "Value %v overflows %s", floatValue, fieldVal.Type(),
+        )
+    }
+    fieldVal.SetFloat(floatValue)
+
+    return nil
+}
+
+func setString(fieldVal, newVal reflect.Value) error {
+    if isByteArray(newVal.Type()) {
+        raw := make([]byte, newVal.Len())
+        reflect.Copy(reflect.ValueOf(raw), newVal)
+        fieldVal.SetString(formatUUIDLike(raw))
+        return nil
+    }
+
+    if stringValue, ok := stringFromValue(newVal); ok {
+        fieldVal.SetString(stringValue)
+        return nil
+    }
+
+    switch newVal.Kind() {
+    case
+        reflect.Int,
+        reflect.Int8,
+        reflect.Int16,
+        reflect.Int32,
+        reflect.Int64:
+        fieldVal.SetString(strconv.FormatInt(newVal.Int(), 10))
+    case
+        reflect.Uint,
+        reflect.Uint8,
+        reflect.Uint16,
+        reflect.Uint32,
+        reflect.Uint64:
+        fieldVal.SetString(strconv.FormatUint(newVal.Uint(), 10))
+    default:
+        return errors.Errorf(
+            "Can't convert %s to %s", newVal.Type(), fieldVal.Type(),
+        )
+    }
+
+    return nil
+}
+
+func setBytes(fieldVal, newVal reflect.Value) error {
+    var raw []byte
+    switch {
+    case isByteSlice(newVal.Type()):
+        raw = append([]byte(nil), newVal.Bytes()...)
+    case isByteArray(newVal.Type()):
+        raw = make([]byte, newVal.Len())
+        reflect.Copy(reflect.ValueOf(raw), newVal)
+    case newVal.Kind() == reflect.String:
+        raw = []byte(newVal.String())
+    default:
+        return errors.Errorf(
+            "Can't convert %s to %s", newVal.Type(), fieldVal.Type(),
+        )
+    }
+
+    fieldVal.SetBytes(raw)
+
+    return nil
+}
+
+func setByteArray(fieldVal, newVal reflect.Value) error {
+    size := fieldVal.Len()
+    var raw []byte
+    switch {
+    case isByteArray(newVal.Type()) || isByteSlice(newVal.Type()):
+        raw = make([]byte, newVal.Len())
+        reflect.Copy(reflect.ValueOf(raw), newVal)
+        if len(raw) != size {
+            // Drivers commonly hand back UUIDs in their text form as bytes.
+            parsed, ok := parseUUIDLike(string(raw), size)
+            if !ok {
+                return errors.Errorf(
+                    "Can't fit %d bytes into %s", len(raw), fieldVal.Type(),
+                )
+            }
+            raw = parsed
+        }
+    case newVal.Kind() == reflect.String:
+        parsed, ok := parseUUIDLike(newVal.String(), size)
+        if !ok {
+            return errors.Errorf(
+                "Can't parse '%s' into %s", newVal.String(), fieldVal.Type(),
+            )
+        }
+        raw = parsed
+    default:
+        return errors.Errorf(
+            "Can't convert %s to %s", newVal.Type(), fieldVal.Type(),
+        )
+    }
+
+    reflect.Copy(fieldVal, reflect.ValueOf(raw))
+
+    return nil
+}
+
+func setBool(fieldVal, newVal reflect.Value) error {
+    switch newVal.Kind() {
+    case reflect.Bool:
+        fieldVal.SetBool(newVal.Bool())
+    case
+        reflect.Int,
+        reflect.Int8,
+        reflect.Int16,
+        reflect.Int32,
+        reflect.Int64:
+        fieldVal.SetBool(newVal.Int() != 0)
+    default:
+        stringValue, ok := stringFromValue(newVal)
+        if !ok {
+            return errors.Errorf(
+                "Can't convert %s to %s", newVal.Type(), fieldVal.Type(),
+            )
+        }
+        parsed, err := strconv.ParseBool(stringValue)
+        if err != nil {
+            return errors.Wrapf(
+                err, "Can't convert '%s' to %s", stringValue, fieldVal.Type(),
+            )
+        }
+        fieldVal.SetBool(parsed)
+    }
+
+    return nil
+}
+
+// convertInto sets a (non-pointer) field to the provided (non-pointer) value
+// converting as appropriate.
+func convertInto(fieldVal, newVal reflect.Value) error {
+    fieldType := fieldVal.Type()
+    if fieldVal.CanAddr() && fieldVal.Addr().Type().Implements(scannerType) {
+        scanner := fieldVal.Addr().Interface().(sql.Scanner)
+        err := scanner.Scan(newVal.Interface())
+        if err != nil {
+            return errors.Wrapf(err, "Error scanning into %s", fieldType)
+        }
+        return nil
+    }
+
+    if newVal.Type().AssignableTo(fieldType) {
+        fieldVal.Set(newVal)
+        return nil
+    }
+
+    switch {
+    case isByteSlice(fieldType):
+        return setBytes(fieldVal, newVal)
+    case isByteArray(fieldType):
+        return setByteArray(fieldVal, newVal)
+    }
+
+    switch fieldType.Kind() {
+    case
+        reflect.Int,
+        reflect.Int8,
+        reflect.Int16,
+        reflect.Int32,
+        reflect.Int64:
+        return setInt(fieldVal, newVal)
+    case
+        reflect.Uint,
+        reflect.Uint8,
+        reflect.Uint16,
+        reflect.Uint32,
+        reflect.Uint64:
+        return setUint(fieldVal, newVal)
+    case reflect.Float32, reflect.Float64:
+        return setFloat(fieldVal, newVal)
+    case reflect.String:
+        return setString(fieldVal, newVal)
+    case reflect.Bool:
+        return setBool(fieldVal, newVal)
+    }
+
+    if newVal.Kind() == fieldType.Kind() &&
+        newVal.Type().ConvertibleTo(fieldType) {
+        fieldVal.Set(newVal.Convert(fieldType))
+        return nil
+    }
+
+    return errors.Errorf(
+        "Can't convert %s to %s", newVal.Type(), fieldType,
+    )
+}
@@ -400,32 +400,60 @@ func ElementTypeFromSlice(sliceIn interface{}) (reflect.Type, error) {
     return elemType, nil
 }
 
+// InitSetField sets the provided field to the provided value, allocating
+// pointers along the way and converting the value where it's safe to do so.
+// Supported conversions include all int/uint widths (with overflow checks),
+// floats, bools, strings, []byte, byte arrays such as UUIDs, sql.Scanner
+// targets and driver.Valuer sources.
 func InitSetField(fieldVal, newVal reflect.Value) error {
+    if !fieldVal.CanSet() {
+        return errors.Errorf("Field of type %s can't be set", fieldVal.Type())
+    }
+
     derefedNewVal := newVal
-    for derefedNewVal.Kind() == reflect.Ptr {
+    for derefedNewVal.IsValid() && derefedNewVal.Kind() == reflect.Ptr {
+        if derefedNewVal.IsNil() {
+            derefedNewVal = reflect.Value{}
+            break
+        }
         derefedNewVal = derefedNewVal.Elem()
     }
-
-    if fieldVal.Kind() == reflect.Ptr && fieldVal.IsNil() {
-        alloc := reflect.New(Deref(fieldVal.Type()))
-        fieldVal.Set(alloc)
+    if !derefedNewVal.IsValid() {
+        fieldVal.Set(reflect.Zero(fieldVal.Type()))
+        return nil
     }
 
     derefedFieldVal := fieldVal
     for derefedFieldVal.Kind() == reflect.Ptr {
+        if derefedFieldVal.IsNil() {
+            alloc := reflect.New(Deref(derefedFieldVal.Type()))
+            derefedFieldVal.Set(alloc)
+        }
         derefedFieldVal = derefedFieldVal.Elem()
     }
 
-    switch k := derefedFieldVal.Kind(); k {
-        // TODO: Add more handled kinds.
-        case reflect.Int64:
-        intValue := derefedNewVal.Interface().(int64)
-        derefedFieldVal.SetInt(intValue)
-        default:
-        return errors.Errorf("Unknown kind: %s", k.String())
+    if derefedNewVal.Type().AssignableTo(derefedFieldVal.Type()) {
+        derefedFieldVal.Set(derefedNewVal)
+        return nil
+    }
+
+    valuerVal := derefedNewVal
+    if !valuerVal.Type().Implements(valuerType) && valuerVal.CanAddr() {
+        valuerVal = valuerVal.Addr()
+    }
+    if valuerVal.Type().Implements(valuerType) {
+        driverVal, err := valueFromValuer(valuerVal)
+        if err != nil {
+            return err
+        }
+        if !driverVal.IsValid() {
+            derefedFieldVal.Set(reflect.Zero(derefedFieldVal.Type()))
+            return nil
+        }
+        derefedNewVal = driverVal
     }
 
-    return nil
+    return convertInto(derefedFieldVal, derefedNewVal)
 }
 
 func IsZeroValue(in interface{}) bool {